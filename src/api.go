@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// apiDefaultPageSize and apiMaxPageSize bound the number of games per page
+	apiDefaultPageSize = 50
+	apiMaxPageSize     = 200
+)
+
+// apiUserKey is the request context key holding the authenticated *User
+type apiUserKey struct{}
+
+// newHTTPServer builds the embedded HTTP server for health checks, metrics, the API
+// and the web dashboard. It
+// listens on HTTP_PORT (8080 by default), which is the port exposed by the Dockerfile.
+func newHTTPServer(discord *discordgo.Session) *http.Server {
+	mux := http.NewServeMux()
+	registerHealthRoutes(mux)
+	registerAPIRoutes(mux, discord)
+	registerDashboardRoutes(mux)
+
+	return &http.Server{
+		Addr:              ":" + AppConfig.HTTPPort,
+		Handler:           withRequestTimeout(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// withRequestTimeout bounds the database work done for each request with the
+// HTTP_REQUEST_TIMEOUT_SECONDS deadline
+func withRequestTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), AppConfig.HTTPRequestTimeout())
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// registerAPIRoutes adds the read-only JSON API. Every route requires an API token
+// issued with the /api-token slash command. User routes only serve the token's owner,
+// and guild routes only members of the guild, checked through the Discord session.
+func registerAPIRoutes(mux *http.ServeMux, discord *discordgo.Session) {
+	mux.Handle("GET /api/me", requireAPIToken(apiMeHandler))
+	mux.Handle("GET /api/users/{discordID}", requireAPIToken(apiUserHandler))
+	mux.Handle("GET /api/users/{discordID}/games", requireAPIToken(apiUserGamesHandler))
+	mux.Handle("GET /api/users/{discordID}/stats", requireAPIToken(apiUserStatsHandler))
+	mux.Handle("GET /api/users/{discordID}/ratings", requireAPIToken(apiUserRatingsHandler))
+	mux.Handle("GET /api/guilds/{guildID}/ratings/{category}", requireAPIToken(requireGuildMember(discord, apiRatingLeaderboardHandler)))
+	mux.Handle("GET /api/guilds/{guildID}/meta", requireAPIToken(requireGuildMember(discord, apiGuildMetaHandler)))
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "not found")
+	})
+}
+
+// requireAPIToken authenticates a request with an "Authorization: Bearer <token>" header
+func requireAPIToken(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			writeAPIError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		user, err := GetUserByAPIToken(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			if !errors.Is(err, ErrInvalidAPIToken) {
+				requestLogger(r).Error("Failed to authenticate api token", "error", err)
+				writeAPIInternalError(w, r)
+				return
+			}
+			writeAPIError(w, http.StatusUnauthorized, "invalid api token")
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), apiUserKey{}, user)))
+	})
+}
+
+// requireGuildMember only lets the token's owner through to a {guildID} route if they
+// are a member of that guild. Guilds the bot isn't in are treated like ones the owner
+// isn't in, so the response doesn't reveal which guilds use the bot.
+func requireGuildMember(discord *discordgo.Session, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner := r.Context().Value(apiUserKey{}).(*User)
+		member, err := isGuildMember(discord, r.PathValue("guildID"), owner.DiscordID)
+		if err != nil {
+			requestLogger(r).Error("Failed to check guild membership", "error", err)
+			writeAPIInternalError(w, r)
+			return
+		}
+		if !member {
+			writeAPIError(w, http.StatusForbidden, "not a member of this guild")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// isGuildMember reports whether a Discord user is a member of a guild, using the
+// session's cached members before asking Discord
+func isGuildMember(discord *discordgo.Session, guildID, discordID string) (bool, error) {
+	if guildID == "" {
+		return false, nil
+	}
+	_, err := discord.State.Member(guildID, discordID)
+	if err == nil {
+		return true, nil
+	}
+
+	_, err = discord.GuildMember(guildID, discordID)
+	if err == nil {
+		return true, nil
+	}
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil &&
+		(restErr.Response.StatusCode == http.StatusNotFound || restErr.Response.StatusCode == http.StatusForbidden) {
+		return false, nil
+	}
+	return false, err
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
+
+// writeAPIError writes a JSON error response
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeAPIInternalError writes the error response for a failed lookup: 504 if the
+// request ran past its deadline, otherwise 500
+func writeAPIInternalError(w http.ResponseWriter, r *http.Request) {
+	if errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+		writeAPIError(w, http.StatusGatewayTimeout, "request timed out")
+		return
+	}
+	writeAPIError(w, http.StatusInternalServerError, "internal error")
+}
+
+// apiPathUser looks up the user named by the {discordID} path value, writing an
+// error response and returning nil if there is none. Tokens only read their owner's
+// data, which includes games logged privately in DMs.
+func apiPathUser(w http.ResponseWriter, r *http.Request) *User {
+	owner := r.Context().Value(apiUserKey{}).(*User)
+	if r.PathValue("discordID") != owner.DiscordID {
+		writeAPIError(w, http.StatusForbidden, "tokens can only read their owner's data")
+		return nil
+	}
+
+	user, err := GetUserByDiscordID(r.Context(), r.PathValue("discordID"))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			writeAPIError(w, http.StatusNotFound, "user not found")
+			return nil
+		}
+		requestLogger(r).Error("Failed to get user", "error", err)
+		writeAPIInternalError(w, r)
+		return nil
+	}
+	return user
+}
+
+// apiCategory reads and validates the optional "category" query parameter
+func apiCategory(w http.ResponseWriter, r *http.Request) (string, bool) {
+	category := r.URL.Query().Get("category")
+	if category == "" {
+		return "", true
+	}
+	if !ValidateCategory(category) {
+		writeAPIError(w, http.StatusBadRequest, "invalid category")
+		return "", false
+	}
+	return NormalizeCategory(category), true
+}
+
+// apiStatsFilter reads the optional "category", "from" and "to" query parameters.
+// Dates are YYYY-MM-DD in the user's timezone.
+func apiStatsFilter(w http.ResponseWriter, r *http.Request, user *User) (StatsFilter, bool) {
+	query := r.URL.Query()
+	filter, err := parseStatsFilter(query.Get("category"), query.Get("from"), query.Get("to"), userLocation(user))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return filter, false
+	}
+	return filter, true
+}
+
+// apiIntParam reads an optional non-negative integer query parameter
+func apiIntParam(w http.ResponseWriter, r *http.Request, name string, fallback int) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s", name))
+		return 0, false
+	}
+	return value, true
+}
+
+func apiMeHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, r.Context().Value(apiUserKey{}).(*User))
+}
+
+func apiUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := apiPathUser(w, r)
+	if user == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+func apiUserGamesHandler(w http.ResponseWriter, r *http.Request) {
+	user := apiPathUser(w, r)
+	if user == nil {
+		return
+	}
+	filter, ok := apiStatsFilter(w, r, user)
+	if !ok {
+		return
+	}
+	limit, ok := apiIntParam(w, r, "limit", apiDefaultPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := apiIntParam(w, r, "offset", 0)
+	if !ok {
+		return
+	}
+	limit = min(max(limit, 1), apiMaxPageSize)
+
+	gameResults, err := GetUserGameResults(r.Context(), user.ID, filter, limit, offset)
+	if err != nil {
+		requestLogger(r).Error("Failed to get user game results", "error", err)
+		writeAPIInternalError(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"games":  gameResults,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func apiUserStatsHandler(w http.ResponseWriter, r *http.Request) {
+	user := apiPathUser(w, r)
+	if user == nil {
+		return
+	}
+	filter, ok := apiStatsFilter(w, r, user)
+	if !ok {
+		return
+	}
+
+	summary, err := GetUserSummary(r.Context(), user.ID, filter)
+	if err != nil {
+		requestLogger(r).Error("Failed to get user summary", "error", err)
+		writeAPIInternalError(w, r)
+		return
+	}
+
+	matchups, err := GetUserMatchups(r.Context(), user.ID, filter)
+	if err != nil {
+		requestLogger(r).Error("Failed to get user matchups", "error", err)
+		writeAPIInternalError(w, r)
+		return
+	}
+
+	loc := userLocation(user)
+	days, err := GetUserDailyResults(r.Context(), user.ID, filter, loc.String())
+	if err != nil {
+		requestLogger(r).Error("Failed to get daily results", "error", err)
+		writeAPIInternalError(w, r)
+		return
+	}
+
+	practiceDays, err := streakDays(r.Context(), user.ID, days, filter, loc.String())
+	if err != nil {
+		requestLogger(r).Error("Failed to get session days", "error", err)
+		writeAPIInternalError(w, r)
+		return
+	}
+
+	practice, err := GetUserPracticeTime(r.Context(), user.ID, filter.From, filter.To)
+	if err != nil {
+		requestLogger(r).Error("Failed to get practice time", "error", err)
+		writeAPIInternalError(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"summary":  summary,
+		"matchups": matchups,
+		"daily":    days,
+		"streak":   calculateStreak(practiceDays, time.Now().In(loc)),
+		"practice": practice,
+	})
+}
+
+func apiUserRatingsHandler(w http.ResponseWriter, r *http.Request) {
+	user := apiPathUser(w, r)
+	if user == nil {
+		return
+	}
+
+	ratings, err := GetUserRatings(r.Context(), user.ID, "")
+	if err != nil {
+		requestLogger(r).Error("Failed to get user ratings", "error", err)
+		writeAPIInternalError(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"ratings": ratings})
+}
+
+func apiRatingLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	category := r.PathValue("category")
+	if !ValidateCategory(category) {
+		writeAPIError(w, http.StatusBadRequest, "invalid category")
+		return
+	}
+	limit, ok := apiIntParam(w, r, "limit", ratingLeaderboardSize)
+	if !ok {
+		return
+	}
+
+	ratings, err := GetRatingLeaderboard(r.Context(), r.PathValue("guildID"), NormalizeCategory(category), min(max(limit, 1), apiMaxPageSize))
+	if err != nil {
+		requestLogger(r).Error("Failed to get rating leaderboard", "error", err)
+		writeAPIInternalError(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"ratings": ratings})
+}
+
+func apiGuildMetaHandler(w http.ResponseWriter, r *http.Request) {
+	category, ok := apiCategory(w, r)
+	if !ok {
+		return
+	}
+	days, ok := apiIntParam(w, r, "days", metaDefaultDays)
+	if !ok {
+		return
+	}
+
+	report, err := GetGuildMeta(r.Context(), r.PathValue("guildID"), min(max(days, 1), 365), category)
+	if err != nil {
+		requestLogger(r).Error("Failed to get guild meta", "error", err)
+		writeAPIInternalError(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+type apiTokenOptions struct {
+	Revoke bool `option:"revoke" description:"Revoke your current token instead of issuing a new one"`
+}
+
+// apiTokenCommand replies privately, since the reply contains a secret
+var apiTokenCommand = &slashCommand[apiTokenOptions]{
+	Name:             "api-token",
+	Description:      "Get a personal token for the read-only JSON API",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Ephemeral:        true,
+	Run:              runAPIToken,
+}
+
+func runAPIToken(ctx context.Context, c *commandContext, options *apiTokenOptions) error {
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed("❌ Failed to manage your API token. Please try again later.", err)
+	}
+
+	if options.Revoke {
+		revoked, err := RevokeAPIToken(ctx, user.ID)
+		if err != nil {
+			return commandFailed("❌ Failed to revoke your API token. Please try again later.", fmt.Errorf("failed to revoke api token: %w", err))
+		}
+		if revoked {
+			c.Reply("✅ Your API token has been revoked.")
+		} else {
+			c.Reply("ℹ️ You don't have an API token.")
+		}
+	} else {
+		token, err := CreateAPIToken(ctx, user.ID)
+		if err != nil {
+			return commandFailed("❌ Failed to create your API token. Please try again later.", fmt.Errorf("failed to create api token: %w", err))
+		}
+		c.Replyf("🔑 **Your API token**\n```\n%s\n```\nSend it as `Authorization: Bearer <token>`, e.g. `GET /api/me`.\nThis is the only time it will be shown, and any previous token no longer works. Keep it secret!", token)
+	}
+
+	c.Logger().Info("API token updated", "username", user.Username, "revoke", options.Revoke)
+	return nil
+}
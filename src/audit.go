@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// auditSource is who made a change and through which interaction, as recorded in the
+// audit log
+type auditSource struct {
+	ActorDiscordID string
+	GuildID        string
+	InteractionID  string
+}
+
+type auditSourceKey struct{}
+
+// withAuditSource tags ctx with the interaction's user, guild and ID, so changes the
+// handler makes are attributed to them in the audit log
+func withAuditSource(ctx context.Context, i *discordgo.InteractionCreate) context.Context {
+	return context.WithValue(ctx, auditSourceKey{}, auditSource{
+		ActorDiscordID: interactionUser(i).ID,
+		GuildID:        i.GuildID,
+		InteractionID:  i.ID,
+	})
+}
+
+// withSystemAuditSource tags ctx for changes the bot makes on its own, such as
+// auto-archiving, so they are still listed under the guild they affect
+func withSystemAuditSource(ctx context.Context, guildID string) context.Context {
+	return context.WithValue(ctx, auditSourceKey{}, auditSource{GuildID: guildID})
+}
+
+// auditSourceFrom returns the audit source ctx was tagged with, or an empty source
+// for changes made outside an interaction
+func auditSourceFrom(ctx context.Context) auditSource {
+	source, _ := ctx.Value(auditSourceKey{}).(auditSource)
+	return source
+}
+
+// administratorPermission restricts reading the audit log to server administrators
+var administratorPermission int64 = discordgo.PermissionAdministrator
+
+const (
+	// auditMessageLength keeps the /audit message under Discord's 2000 character limit;
+	// entries past it are only in the attached file
+	auditMessageLength = 1800
+)
+
+// auditActionChoices offers the audit actions as command option choices
+func auditActionChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(AuditActions))
+	for _, action := range AuditActions {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: action, Value: action})
+	}
+	return choices
+}
+
+type auditOptions struct {
+	User   *discordgo.User `option:"user" description:"Only show changes made by this member"`
+	Action string          `option:"action" description:"Only show this kind of change" choices:"audit-action"`
+	Limit  int             `option:"limit" description:"How many entries to show" default:"20" min:"1" max:"50"`
+}
+
+var auditCommand = &slashCommand[auditOptions]{
+	Name:             "audit",
+	Description:      "Show recent changes made to this server's data",
+	Permissions:      &administratorPermission,
+	Contexts:         guildOnlyContexts,
+	IntegrationTypes: guildInstallTypes,
+	Ephemeral:        true,
+	Run:              runAudit,
+}
+
+// formatAuditEntry describes an audit entry on one line
+func formatAuditEntry(entry *AuditEntry) string {
+	line := fmt.Sprintf("• <t:%d:f> **%s**", entry.CreatedAt.Unix(), entry.Action)
+	if entry.ActorDiscordID != "" {
+		line += fmt.Sprintf(" by <@%s>", entry.ActorDiscordID)
+	}
+	if entry.TargetType != "" && entry.TargetID != nil {
+		line += fmt.Sprintf(" • %s #%d", entry.TargetType, *entry.TargetID)
+	}
+	return line
+}
+
+func runAudit(ctx context.Context, c *commandContext, options *auditOptions) error {
+	filter := AuditFilter{GuildID: c.GuildID(), Action: options.Action, Limit: options.Limit}
+	if options.User != nil {
+		filter.ActorDiscordID = options.User.ID
+	}
+
+	entries, err := GetAuditEntries(ctx, filter)
+	if err != nil {
+		return commandFailed("❌ Failed to load the audit log. Please try again later.", fmt.Errorf("failed to get audit entries: %w", err))
+	}
+
+	if len(entries) == 0 {
+		c.Reply("📜 No matching changes in the audit log.")
+		return nil
+	}
+
+	var lines []string
+	length := 0
+	for idx, entry := range entries {
+		line := formatAuditEntry(entry)
+		length += len(line) + 1
+		if length > auditMessageLength {
+			lines = append(lines, fmt.Sprintf("…and %d more in the attached file", len(entries)-idx))
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	// The before and after snapshots don't fit in a message, so they come as a file
+	snapshots, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return commandFailed("❌ Failed to load the audit log. Please try again later.", fmt.Errorf("failed to encode audit entries: %w", err))
+	}
+
+	c.Send(&discordgo.WebhookParams{
+		Content:         fmt.Sprintf("📜 **Audit log** (latest %d)\n\n%s", len(entries), strings.Join(lines, "\n")),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+		Files: []*discordgo.File{
+			{
+				Name:        "audit.json",
+				ContentType: "application/json",
+				Reader:      bytes.NewReader(snapshots),
+			},
+		},
+	})
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxGuildCategories is the most categories a server can have
+const maxGuildCategories = 50
+
+// manageGuildPermission restricts changing a server's categories to its managers
+var manageGuildPermission int64 = discordgo.PermissionManageGuild
+
+// suggestCategories suggests the categories of the server the interaction came from
+func suggestCategories(ctx context.Context, i *discordgo.InteractionCreate) ([]string, error) {
+	return GetGuildCategories(ctx, i.GuildID)
+}
+
+// resolveGuildCategory matches a category the user typed against the server's
+// categories and returns it in its stored spelling. An empty name resolves to the
+// default category, or the server's first category if it removed the default.
+func resolveGuildCategory(ctx context.Context, c *commandContext, name string) (string, error) {
+	categories, err := GetGuildCategories(ctx, c.GuildID())
+	if err != nil {
+		return "", commandFailed("❌ Failed to load this server's categories. Please try again later.", err)
+	}
+
+	if name == "" {
+		for _, category := range categories {
+			if category == DefaultCategory {
+				return category, nil
+			}
+		}
+		return categories[0], nil
+	}
+
+	for _, category := range categories {
+		if strings.EqualFold(category, name) {
+			return category, nil
+		}
+	}
+	return "", userError("❌ `%s` isn't a category here. Pick one of: %s", name, strings.Join(categories, ", "))
+}
+
+var categoryCommand = &commandGroup{
+	Name:             "category",
+	Description:      "Manage the game categories on this server",
+	Contexts:         guildOnlyContexts,
+	IntegrationTypes: guildInstallTypes,
+	Subcommands: []subcommandSpec{
+		categoryListCommand,
+		categoryAddCommand,
+		categoryRemoveCommand,
+	},
+}
+
+var categoryListCommand = &slashCommand[noOptions]{
+	Name:        "list",
+	Description: "List the categories games can be recorded in",
+	Run:         runCategoryList,
+}
+
+func runCategoryList(ctx context.Context, c *commandContext, options *noOptions) error {
+	categories, err := GetGuildCategories(ctx, c.GuildID())
+	if err != nil {
+		return commandFailed("❌ Failed to load this server's categories. Please try again later.", err)
+	}
+
+	lines := make([]string, 0, len(categories))
+	for _, category := range categories {
+		lines = append(lines, fmt.Sprintf("• %s", category))
+	}
+	c.Replyf("📂 **Game Categories** (%d)\n\n%s", len(categories), strings.Join(lines, "\n"))
+	return nil
+}
+
+type categoryAddOptions struct {
+	Name string `option:"name" description:"The new category, such as a store name or event" required:"true"`
+}
+
+func (o *categoryAddOptions) validate() error {
+	if !ValidateCategory(o.Name) {
+		return fmt.Errorf("❌ Category names must be 1 to %d characters long.", maxCategoryLength)
+	}
+	return nil
+}
+
+var categoryAddCommand = &slashCommand[categoryAddOptions]{
+	Name:        "add",
+	Description: "Add a category games can be recorded in",
+	Permissions: &manageGuildPermission,
+	Run:         runCategoryAdd,
+}
+
+func runCategoryAdd(ctx context.Context, c *commandContext, options *categoryAddOptions) error {
+	name := NormalizeCategory(options.Name)
+
+	categories, err := GetGuildCategories(ctx, c.GuildID())
+	if err != nil {
+		return commandFailed("❌ Failed to add the category. Please try again later.", err)
+	}
+	if len(categories) >= maxGuildCategories {
+		return userError("❌ This server already has %d categories. Remove one before adding another.", maxGuildCategories)
+	}
+
+	added, err := AddGuildCategory(ctx, c.GuildID(), name)
+	if err != nil {
+		return commandFailed("❌ Failed to add the category. Please try again later.", fmt.Errorf("failed to add guild category: %w", err))
+	}
+	if !added {
+		return userError("❌ **%s** is already a category here.", name)
+	}
+
+	c.Replyf("✅ Added the **%s** category.", name)
+
+	c.Logger().Info("Category added", "category", name)
+	return nil
+}
+
+type categoryRemoveOptions struct {
+	Name string `option:"name" description:"The category to remove" required:"true" autocomplete:"category"`
+}
+
+var categoryRemoveCommand = &slashCommand[categoryRemoveOptions]{
+	Name:        "remove",
+	Description: "Remove a category. Games already recorded in it keep it.",
+	Permissions: &manageGuildPermission,
+	Run:         runCategoryRemove,
+}
+
+func runCategoryRemove(ctx context.Context, c *commandContext, options *categoryRemoveOptions) error {
+	removed, err := RemoveGuildCategory(ctx, c.GuildID(), options.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCategoryNotFound):
+			return userError("❌ `%s` isn't a category here.", options.Name)
+		case errors.Is(err, ErrLastCategory):
+			return userError("❌ A server needs at least one category. Add another before removing **%s**.", options.Name)
+		}
+		return commandFailed("❌ Failed to remove the category. Please try again later.", fmt.Errorf("failed to remove guild category: %w", err))
+	}
+
+	c.Replyf("✅ Removed the **%s** category. Games already recorded in it keep it.", removed)
+
+	c.Logger().Info("Category removed", "category", removed)
+	return nil
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+// Chart colours, chosen to read well on both light and dark Discord themes
+var (
+	chartBackground = color.RGBA{0x2b, 0x2d, 0x31, 0xff}
+	chartForeground = color.RGBA{0xf2, 0xf3, 0xf5, 0xff}
+	chartMuted      = color.RGBA{0x94, 0x9b, 0xa4, 0xff}
+	chartGrid       = color.RGBA{0x3f, 0x42, 0x48, 0xff}
+	chartLine       = color.RGBA{0x58, 0x65, 0xf2, 0xff}
+	chartEmptyCell  = color.RGBA{0x38, 0x3a, 0x40, 0xff}
+	chartLossColor  = color.RGBA{0xed, 0x42, 0x45, 0xff}
+	chartEvenColor  = color.RGBA{0xfe, 0xe7, 0x5c, 0xff}
+	chartWinColor   = color.RGBA{0x57, 0xf2, 0x87, 0xff}
+)
+
+// chartCalendarLevels are the fill colours of the calendar heatmap, from one game
+// a day up to a busy practice day
+var chartCalendarLevels = []color.RGBA{
+	{0x0e, 0x44, 0x29, 0xff},
+	{0x00, 0x6d, 0x32, 0xff},
+	{0x26, 0xa6, 0x41, 0xff},
+	{0x39, 0xd3, 0x53, 0xff},
+}
+
+// WinRatePoint is one point on the win-rate-over-time chart
+type WinRatePoint struct {
+	Day     time.Time
+	WinRate float64
+}
+
+// newChartImage creates a chart canvas filled with the background colour
+func newChartImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+	return img
+}
+
+// encodeChart encodes a chart as PNG
+func encodeChart(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fillRect fills the rectangle with its top-left corner at (x, y)
+func fillRect(img *image.RGBA, x, y, width, height int, c color.Color) {
+	draw.Draw(img, image.Rect(x, y, x+width, y+height), &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// drawLine draws a line of the given thickness using Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1, thickness int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	offset := thickness / 2
+	e := dx + dy
+	for {
+		fillRect(img, x0-offset, y0-offset, thickness, thickness, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// lerpColor blends between two colours, t in [0, 1]
+func lerpColor(from, to color.RGBA, t float64) color.RGBA {
+	blend := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*t)
+	}
+	return color.RGBA{blend(from.R, to.R), blend(from.G, to.G), blend(from.B, to.B), 0xff}
+}
+
+// winRateColor maps a win rate in [0, 1] from red through yellow to green
+func winRateColor(winRate float64) color.RGBA {
+	if winRate < 0.5 {
+		return lerpColor(chartLossColor, chartEvenColor, winRate*2)
+	}
+	return lerpColor(chartEvenColor, chartWinColor, (winRate-0.5)*2)
+}
+
+// renderWinRateChart draws a line chart of win rate (0-100%) over time
+func renderWinRateChart(title string, points []WinRatePoint) ([]byte, error) {
+	const (
+		width, height = 800, 400
+		left, right   = 60, 30
+		top, bottom   = 50, 50
+	)
+	img := newChartImage(width, height)
+	drawChartText(img, left, 16, title, 2, chartForeground)
+
+	plotWidth, plotHeight := width-left-right, height-top-bottom
+	yFor := func(winRate float64) int {
+		return top + plotHeight - int(winRate/100*float64(plotHeight))
+	}
+
+	// Horizontal grid lines every 25%
+	for pct := 0; pct <= 100; pct += 25 {
+		y := yFor(float64(pct))
+		gridColor := chartGrid
+		if pct == 50 {
+			gridColor = chartMuted
+		}
+		drawLine(img, left, y, left+plotWidth, y, 1, gridColor)
+		label := fmt.Sprintf("%d%%", pct)
+		drawChartText(img, left-8-chartTextWidth(label, 1), y-3, label, 1, chartMuted)
+	}
+
+	if len(points) == 0 {
+		drawChartText(img, left+plotWidth/2-chartTextWidth("NO GAMES YET", 2)/2, top+plotHeight/2-7, "NO GAMES YET", 2, chartMuted)
+		return encodeChart(img)
+	}
+
+	first, last := points[0].Day, points[len(points)-1].Day
+	span := last.Sub(first)
+	xFor := func(day time.Time) int {
+		if span <= 0 {
+			return left + plotWidth/2
+		}
+		return left + int(float64(plotWidth)*float64(day.Sub(first))/float64(span))
+	}
+
+	for idx := 1; idx < len(points); idx++ {
+		drawLine(img, xFor(points[idx-1].Day), yFor(points[idx-1].WinRate),
+			xFor(points[idx].Day), yFor(points[idx].WinRate), 3, chartLine)
+	}
+	for _, point := range points {
+		fillRect(img, xFor(point.Day)-3, yFor(point.WinRate)-3, 7, 7, chartLine)
+	}
+
+	// Date labels at both ends and the middle of the x axis
+	labelY := top + plotHeight + 14
+	firstLabel, lastLabel := first.Format("Jan 2"), last.Format("Jan 2")
+	drawChartText(img, left, labelY, firstLabel, 1, chartMuted)
+	if span > 0 {
+		middle := first.Add(span / 2).Format("Jan 2")
+		drawChartText(img, left+plotWidth/2-chartTextWidth(middle, 1)/2, labelY, middle, 1, chartMuted)
+		drawChartText(img, left+plotWidth-chartTextWidth(lastLabel, 1), labelY, lastLabel, 1, chartMuted)
+	}
+
+	return encodeChart(img)
+}
+
+// renderMatchupHeatmap draws a grid of the user's leaders (rows) against opponent
+// leaders (columns), coloured by win rate
+func renderMatchupHeatmap(title string, leaders, opponents []string, records map[[2]string]*MatchupRecord) ([]byte, error) {
+	const (
+		cellWidth, cellHeight = 64, 32
+		labelWidth            = 110
+		headerHeight          = 80
+		margin                = 20
+	)
+	// Opponent labels may overhang their column by half a cell on either side
+	width := margin*2 + labelWidth + cellWidth*max(len(opponents), 1) + cellWidth/2
+	height := headerHeight + cellHeight*max(len(leaders), 1) + margin*2
+	width = max(width, chartTextWidth(title, 2)+margin*2)
+	img := newChartImage(width, height)
+	drawChartText(img, margin, 16, title, 2, chartForeground)
+
+	if len(leaders) == 0 || len(opponents) == 0 {
+		drawChartText(img, margin, headerHeight, "NO GAMES YET", 2, chartMuted)
+		return encodeChart(img)
+	}
+
+	gridLeft, gridTop := margin+labelWidth, headerHeight
+	drawChartText(img, margin, gridTop-14, "YOU VS", 1, chartMuted)
+
+	// Opponent labels, alternating rows so long names don't collide
+	for col, opponent := range opponents {
+		label := chartTruncate(opponent, cellWidth*2-8, 1)
+		y := gridTop - 36
+		if col%2 == 1 {
+			y = gridTop - 24
+		}
+		x := gridLeft + col*cellWidth + cellWidth/2 - chartTextWidth(label, 1)/2
+		drawChartText(img, max(x, gridLeft), y, label, 1, chartForeground)
+	}
+
+	for row, leader := range leaders {
+		y := gridTop + row*cellHeight
+		drawChartText(img, margin, y+cellHeight/2-3, chartTruncate(leader, labelWidth-8, 1), 1, chartForeground)
+
+		for col, opponent := range opponents {
+			x := gridLeft + col*cellWidth
+			record := records[[2]string{leader, opponent}]
+			if record == nil || record.Games == 0 {
+				fillRect(img, x+1, y+1, cellWidth-2, cellHeight-2, chartEmptyCell)
+				continue
+			}
+
+			fillRect(img, x+1, y+1, cellWidth-2, cellHeight-2, winRateColor(matchupWinRate(record)))
+			label := formatRecord(record.Wins, record.Losses, record.Draws)
+			drawChartText(img, x+cellWidth/2-chartTextWidth(label, 1)/2, y+cellHeight/2-3, label, 1, chartBackground)
+		}
+	}
+
+	return encodeChart(img)
+}
+
+// matchupWinRate is a heatmap cell's win rate in [0, 1]. Cells with only draws are
+// shown as even.
+func matchupWinRate(record *MatchupRecord) float64 {
+	if record.Wins+record.Losses == 0 {
+		return 0.5
+	}
+	return winRatePercent(record.Wins, record.Losses) / 100
+}
+
+// renderCalendarHeatmap draws games per day as a calendar of the given number of
+// weeks ending on `end`, one column per week and one row per weekday
+func renderCalendarHeatmap(title string, gamesPerDay map[string]int, end time.Time, weeks int) ([]byte, error) {
+	const (
+		cell, gap   = 14, 3
+		left, top   = 50, 64
+		margin      = 20
+		legendSpace = 30
+	)
+	width := left + weeks*(cell+gap) + margin
+	height := top + 7*(cell+gap) + legendSpace + margin
+	img := newChartImage(width, height)
+	drawChartText(img, margin, 16, title, 2, chartForeground)
+
+	// Weeks start on Monday; the last column holds the week containing `end`
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	weekday := (int(end.Weekday()) + 6) % 7
+	start := end.AddDate(0, 0, -weekday-(weeks-1)*7)
+
+	for row, name := range []string{"MON", "", "WED", "", "FRI", "", "SUN"} {
+		drawChartText(img, margin, top+row*(cell+gap)+3, name, 1, chartMuted)
+	}
+
+	lastMonth, lastLabelX := -1, -width
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		offset := int(day.Sub(start).Hours()/24 + 0.5)
+		col, row := offset/7, offset%7
+		x, y := left+col*(cell+gap), top+row*(cell+gap)
+
+		// Label the first week of each month, unless it would overlap the previous label
+		if row == 0 && int(day.Month()) != lastMonth {
+			if x-lastLabelX >= 3*(cell+gap) {
+				drawChartText(img, x, top-16, day.Format("Jan"), 1, chartMuted)
+				lastLabelX = x
+			}
+			lastMonth = int(day.Month())
+		}
+
+		fillRect(img, x, y, cell, cell, calendarLevelColor(gamesPerDay[day.Format("2006-01-02")]))
+	}
+
+	// Legend
+	legendY := top + 7*(cell+gap) + 10
+	drawChartText(img, left, legendY+3, "LESS", 1, chartMuted)
+	x := left + chartTextWidth("LESS", 1) + 8
+	for _, games := range []int{0, 1, 2, 4, 7} {
+		fillRect(img, x, legendY, cell, cell, calendarLevelColor(games))
+		x += cell + gap
+	}
+	drawChartText(img, x+5, legendY+3, "MORE", 1, chartMuted)
+
+	return encodeChart(img)
+}
+
+// calendarLevelColor picks the calendar heatmap colour for a number of games in a day
+func calendarLevelColor(games int) color.RGBA {
+	switch {
+	case games <= 0:
+		return chartEmptyCell
+	case games == 1:
+		return chartCalendarLevels[0]
+	case games <= 3:
+		return chartCalendarLevels[1]
+	case games <= 6:
+		return chartCalendarLevels[2]
+	default:
+		return chartCalendarLevels[3]
+	}
+}
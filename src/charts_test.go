@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+)
+
+// decodeChart decodes a rendered chart, failing the test if it isn't a PNG
+func decodeChart(t *testing.T, data []byte, err error) image.Image {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("chart isn't a valid PNG: %v", err)
+	}
+	return img
+}
+
+func TestChartsRenderPNGs(t *testing.T) {
+	records := map[[2]string]*MatchupRecord{
+		{"Luffy", "Nami"}: {Games: 3, Wins: 3},
+		{"Zoro", "Kid"}:   {Games: 2, Wins: 1, Losses: 1},
+	}
+	end := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	points := []WinRatePoint{
+		{Day: end.AddDate(0, 0, -14), WinRate: 40},
+		{Day: end.AddDate(0, 0, -7), WinRate: 55},
+		{Day: end, WinRate: 62.5},
+	}
+
+	tests := []struct {
+		name          string
+		render        func() ([]byte, error)
+		width, height int
+	}{
+		{"win rate", func() ([]byte, error) { return renderWinRateChart("WIN RATE", points) }, 800, 400},
+		{"win rate without games", func() ([]byte, error) { return renderWinRateChart("WIN RATE", nil) }, 800, 400},
+		{"matchup heatmap", func() ([]byte, error) {
+			return renderMatchupHeatmap("M", []string{"Luffy", "Zoro"}, []string{"Nami", "Kid"}, records)
+		}, 310, 184},
+		{"matchup heatmap without games", func() ([]byte, error) { return renderMatchupHeatmap("M", nil, nil, nil) }, 246, 152},
+		{"calendar heatmap", func() ([]byte, error) {
+			return renderCalendarHeatmap("STREAK", map[string]int{"2026-10-19": 3}, end, 12)
+		}, 274, 233},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.render()
+			img := decodeChart(t, data, err)
+
+			if size := img.Bounds().Size(); size.X != tt.width || size.Y != tt.height {
+				t.Errorf("chart is %dx%d, want %dx%d", size.X, size.Y, tt.width, tt.height)
+			}
+			if got := img.At(0, 0); got != chartBackground {
+				t.Errorf("corner pixel = %v, want the background colour", got)
+			}
+		})
+	}
+}
+
+func TestMatchupHeatmapColoursCellsByWinRate(t *testing.T) {
+	records := map[[2]string]*MatchupRecord{
+		{"Luffy", "Nami"}: {Games: 3, Wins: 3},
+		{"Luffy", "Kid"}:  {Games: 2, Losses: 2},
+	}
+	data, err := renderMatchupHeatmap("M", []string{"Luffy"}, []string{"Nami", "Kid"}, records)
+	img := decodeChart(t, data, err)
+
+	// Cells start at margin+labelWidth across and headerHeight down, 64x32 each
+	if got := img.At(130+3, 80+3); got != chartWinColor {
+		t.Errorf("all-wins cell = %v, want %v", got, chartWinColor)
+	}
+	if got := img.At(130+64+3, 80+3); got != chartLossColor {
+		t.Errorf("all-losses cell = %v, want %v", got, chartLossColor)
+	}
+}
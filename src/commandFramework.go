@@ -0,0 +1,624 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Slash commands are declared once as a slashCommand with an options struct. The
+// struct's tagged fields generate the command's options and receive the values the
+// user typed:
+//
+//	type recordGameOptions struct {
+//		Leader   string `option:"leader" description:"Your leader/character" required:"true"`
+//		Category string `option:"category" description:"Game category" autocomplete:"category"`
+//	}
+//
+// Supported field types are string, int, float64, bool, *bool and *discordgo.User.
+// A *bool stays nil when the option is left out, for yes/no options where "not
+// answered" matters. Tags:
+//   - option:       the option name (fields without it are ignored)
+//   - description:  the option description
+//   - required:     "true" if the user must fill it in
+//   - choices:      a named choice set from optionChoiceSets; values are matched
+//     case-insensitively and bound in their canonical spelling
+//   - autocomplete: a named suggestion source from optionAutocompleters. The user
+//     can still type anything, so Run must check the value.
+//   - default:      the value bound when the option is left out
+//   - min, max:     bounds for int and float64 options
+//
+// If *T has a validate() error method it runs after binding, and its error is shown
+// to the user as-is.
+
+// optionChoiceSets are the fixed choice lists options can refer to by name
+var optionChoiceSets = map[string]func() []*discordgo.ApplicationCommandOptionChoice{
+	"outcome":      outcomeChoices,
+	"audit-action": auditActionChoices,
+}
+
+// maxAutocompleteChoices is the most suggestions Discord will show
+const maxAutocompleteChoices = 25
+
+// optionSuggester lists the values to suggest for an option. The framework narrows
+// them down to the ones matching what the user has typed so far.
+type optionSuggester func(ctx context.Context, i *discordgo.InteractionCreate) ([]string, error)
+
+// optionAutocompleters are the suggestion sources options can refer to by name
+var optionAutocompleters = map[string]optionSuggester{
+	"category":   suggestCategories,
+	"game-group": suggestGameGroups,
+}
+
+// commandSpec is a top-level command: it generates its definition and handles its
+// interactions
+type commandSpec interface {
+	definition() *discordgo.ApplicationCommand
+	handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate)
+	autocomplete(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate)
+}
+
+// subcommandSpec is a command that can also sit under a commandGroup
+type subcommandSpec interface {
+	commandName() string
+	subcommandOption() *discordgo.ApplicationCommandOption
+	requiredPermissions() *int64
+	deferEphemeral() bool
+	run(ctx context.Context, c *commandContext, options []*discordgo.ApplicationCommandInteractionDataOption)
+	suggest(ctx context.Context, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) []*discordgo.ApplicationCommandOptionChoice
+}
+
+// slashCommand is a chat command whose options are bound into a T
+type slashCommand[T any] struct {
+	Name        string
+	Description string
+
+	// Permissions, Contexts and IntegrationTypes are copied to the definition. Under a
+	// commandGroup, Permissions is checked when the subcommand runs instead, since
+	// Discord only restricts whole commands.
+	Permissions      *int64
+	Contexts         *[]discordgo.InteractionContextType
+	IntegrationTypes *[]discordgo.ApplicationIntegrationType
+
+	// Ephemeral makes every reply visible only to the invoking user
+	Ephemeral bool
+
+	// Run does the work after the interaction has been deferred and the options
+	// bound. Returning an error sends the user an error followup; see commandError.
+	Run func(ctx context.Context, c *commandContext, options *T) error
+}
+
+func (cmd *slashCommand[T]) commandName() string         { return cmd.Name }
+func (cmd *slashCommand[T]) requiredPermissions() *int64 { return cmd.Permissions }
+func (cmd *slashCommand[T]) deferEphemeral() bool        { return cmd.Ephemeral }
+
+func (cmd *slashCommand[T]) definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:                     cmd.Name,
+		Description:              cmd.Description,
+		DefaultMemberPermissions: cmd.Permissions,
+		Contexts:                 cmd.Contexts,
+		IntegrationTypes:         cmd.IntegrationTypes,
+		Options:                  optionDefinitions(reflect.TypeFor[T]()),
+	}
+}
+
+func (cmd *slashCommand[T]) subcommandOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        cmd.Name,
+		Description: cmd.Description,
+		Options:     optionDefinitions(reflect.TypeFor[T]()),
+	}
+}
+
+func (cmd *slashCommand[T]) handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	c, ok := beginCommand(s, i, cmd.Ephemeral)
+	if !ok {
+		return
+	}
+	cmd.run(ctx, c, i.ApplicationCommandData().Options)
+}
+
+func (cmd *slashCommand[T]) run(ctx context.Context, c *commandContext, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	bound := new(T)
+	err := bindOptions(c.Interaction, options, bound)
+	if err == nil {
+		if validator, ok := any(bound).(interface{ validate() error }); ok {
+			err = validator.validate()
+			if err != nil {
+				err = userError("%s", err.Error())
+			}
+		}
+	}
+	if err == nil {
+		err = cmd.Run(ctx, c, bound)
+	}
+	if err != nil {
+		c.fail(ctx, err)
+	}
+}
+
+func (cmd *slashCommand[T]) autocomplete(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	respondAutocomplete(s, i, cmd.suggest(ctx, i, i.ApplicationCommandData().Options))
+}
+
+func (cmd *slashCommand[T]) suggest(ctx context.Context, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) []*discordgo.ApplicationCommandOptionChoice {
+	return suggestOption(ctx, i, reflect.TypeFor[T](), options)
+}
+
+// commandGroup is a command made of subcommands, such as /category add and
+// /category list. Each subcommand is a slashCommand with its own options.
+type commandGroup struct {
+	Name        string
+	Description string
+
+	Permissions      *int64
+	Contexts         *[]discordgo.InteractionContextType
+	IntegrationTypes *[]discordgo.ApplicationIntegrationType
+
+	Subcommands []subcommandSpec
+}
+
+func (g *commandGroup) definition() *discordgo.ApplicationCommand {
+	options := make([]*discordgo.ApplicationCommandOption, 0, len(g.Subcommands))
+	for _, sub := range g.Subcommands {
+		options = append(options, sub.subcommandOption())
+	}
+	return &discordgo.ApplicationCommand{
+		Name:                     g.Name,
+		Description:              g.Description,
+		DefaultMemberPermissions: g.Permissions,
+		Contexts:                 g.Contexts,
+		IntegrationTypes:         g.IntegrationTypes,
+		Options:                  options,
+	}
+}
+
+// subcommand finds the subcommand an interaction invoked and the options given to it
+func (g *commandGroup) subcommand(i *discordgo.InteractionCreate) (subcommandSpec, []*discordgo.ApplicationCommandInteractionDataOption) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return nil, nil
+	}
+	for _, sub := range g.Subcommands {
+		if sub.commandName() == options[0].Name {
+			return sub, options[0].Options
+		}
+	}
+	return nil, nil
+}
+
+func (g *commandGroup) handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	sub, options := g.subcommand(i)
+	if sub == nil {
+		respondEphemeralError(ctx, s, i, "❌ Unknown subcommand.")
+		return
+	}
+	if required := sub.requiredPermissions(); required != nil && !hasPermissions(i, *required) {
+		respondEphemeralError(ctx, s, i, "❌ You don't have permission to use this subcommand.")
+		return
+	}
+
+	c, ok := beginCommand(s, i, sub.deferEphemeral())
+	if !ok {
+		return
+	}
+	sub.run(ctx, c, options)
+}
+
+func (g *commandGroup) autocomplete(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	if sub, options := g.subcommand(i); sub != nil {
+		choices = sub.suggest(ctx, i, options)
+	}
+	respondAutocomplete(s, i, choices)
+}
+
+// hasPermissions reports whether the invoking member has all of the given permissions
+// in the channel. Administrators have every permission.
+func hasPermissions(i *discordgo.InteractionCreate, permissions int64) bool {
+	if i.Member == nil {
+		return false
+	}
+	granted := i.Member.Permissions
+	return granted&discordgo.PermissionAdministrator != 0 || granted&permissions == permissions
+}
+
+// commandDefinitions returns the definitions to register with Discord
+func commandDefinitions(specs []commandSpec) []*discordgo.ApplicationCommand {
+	definitions := make([]*discordgo.ApplicationCommand, 0, len(specs))
+	for _, spec := range specs {
+		definitions = append(definitions, spec.definition())
+	}
+	return definitions
+}
+
+// commandHandlers maps each command name to its handler for the router
+func commandHandlers(specs []commandSpec) map[string]interactionHandler {
+	handlers := make(map[string]interactionHandler, len(specs))
+	for _, spec := range specs {
+		handlers[spec.definition().Name] = spec.handle
+	}
+	return handlers
+}
+
+// commandAutocompleters maps each command name to its autocomplete handler for the router
+func commandAutocompleters(specs []commandSpec) map[string]interactionHandler {
+	handlers := make(map[string]interactionHandler, len(specs))
+	for _, spec := range specs {
+		handlers[spec.definition().Name] = spec.autocomplete
+	}
+	return handlers
+}
+
+// suggestOption finds the option the user is typing in and suggests values for it from
+// the field's autocomplete source
+func suggestOption(ctx context.Context, i *discordgo.InteractionCreate, t reflect.Type, options []*discordgo.ApplicationCommandInteractionDataOption) []*discordgo.ApplicationCommandOptionChoice {
+	var focused *discordgo.ApplicationCommandInteractionDataOption
+	for _, option := range options {
+		if option.Focused {
+			focused = option
+		}
+	}
+	if focused == nil {
+		return nil
+	}
+
+	var suggester optionSuggester
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		if field.Tag.Get("option") == focused.Name {
+			suggester = optionAutocompleters[field.Tag.Get("autocomplete")]
+		}
+	}
+	if suggester == nil {
+		return nil
+	}
+
+	values, err := suggester(ctx, i)
+	if err != nil {
+		interactionLogger(i).Error("Failed to get autocomplete suggestions", "option", focused.Name, "error", err)
+		return nil
+	}
+
+	typed := strings.ToLower(strings.TrimSpace(focused.StringValue()))
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, maxAutocompleteChoices)
+	for _, value := range values {
+		if len(choices) == maxAutocompleteChoices {
+			break
+		}
+		if strings.Contains(strings.ToLower(value), typed) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: value, Value: value})
+		}
+	}
+	return choices
+}
+
+// respondAutocomplete answers an autocomplete request. An empty list tells Discord
+// there is nothing to suggest.
+func respondAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, choices []*discordgo.ApplicationCommandOptionChoice) {
+	if choices == nil {
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+	if err != nil {
+		interactionLogger(i).Error("Failed to respond to autocomplete", "error", err)
+	}
+}
+
+// commandContext is what a command's Run function uses to talk back to Discord
+type commandContext struct {
+	Discord     *discordgo.Session
+	Interaction *discordgo.InteractionCreate
+
+	ephemeral bool
+	user      *User
+}
+
+// beginCommand defers the interaction so the command has time to work, then returns
+// the context Run replies through
+func beginCommand(s *discordgo.Session, i *discordgo.InteractionCreate, ephemeral bool) (*commandContext, bool) {
+	interactionLogger(i).Info("Command executed")
+
+	response := &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}
+	if ephemeral {
+		response.Data = &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral}
+	}
+	err := s.InteractionRespond(i.Interaction, response)
+	if err != nil {
+		interactionLogger(i).Error("Failed to defer interaction response", "error", err)
+		return nil, false
+	}
+
+	return &commandContext{Discord: s, Interaction: i, ephemeral: ephemeral}, true
+}
+
+// Invoker is the Discord user who ran the command
+func (c *commandContext) Invoker() *discordgo.User {
+	return interactionUser(c.Interaction)
+}
+
+// GuildID is the server the command ran in, or "" in DMs
+func (c *commandContext) GuildID() string {
+	return c.Interaction.GuildID
+}
+
+// User returns the stored user for the invoker, creating it on first use
+func (c *commandContext) User(ctx context.Context) (*User, error) {
+	if c.user != nil {
+		return c.user, nil
+	}
+	user, err := getOrCreateInteractionUser(ctx, c.Interaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get or create user: %w", err)
+	}
+	c.user = user
+	return user, nil
+}
+
+// Logger returns the interaction's logger
+func (c *commandContext) Logger() *slog.Logger {
+	return interactionLogger(c.Interaction)
+}
+
+// Reply sends a followup message. Mentions in it are shown but never ping anyone.
+func (c *commandContext) Reply(content string) {
+	c.Send(&discordgo.WebhookParams{
+		Content:         content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+// Replyf formats and sends a followup message
+func (c *commandContext) Replyf(format string, args ...any) {
+	c.Reply(fmt.Sprintf(format, args...))
+}
+
+// Send sends a followup with full control over its contents
+func (c *commandContext) Send(params *discordgo.WebhookParams) {
+	if c.ephemeral {
+		params.Flags |= discordgo.MessageFlagsEphemeral
+	}
+	_, err := c.Discord.FollowupMessageCreate(c.Interaction.Interaction, true, params)
+	if err != nil {
+		c.Logger().Error("Failed to send followup message", "error", err)
+	}
+}
+
+// fail reports a Run error to the user. commandErrors carry their own message and
+// are only logged when they wrap an underlying error; anything else is logged and
+// answered with the generic error message.
+func (c *commandContext) fail(ctx context.Context, err error) {
+	var cmdErr *commandError
+	if !errors.As(err, &cmdErr) {
+		c.Logger().Error("Command failed", "error", err)
+		sendErrorFollowup(ctx, c.Discord, c.Interaction, genericErrorMessage)
+		return
+	}
+
+	if cmdErr.err != nil {
+		c.Logger().Error("Command failed", "error", cmdErr.err)
+	}
+	sendErrorFollowup(ctx, c.Discord, c.Interaction, cmdErr.message)
+}
+
+// commandError is returned from Run to show the user a specific message
+type commandError struct {
+	message string
+	err     error
+}
+
+func (e *commandError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return e.message
+}
+
+func (e *commandError) Unwrap() error {
+	return e.err
+}
+
+// userError reports a problem with the user's input. Nothing is logged.
+func userError(format string, args ...any) error {
+	return &commandError{message: fmt.Sprintf(format, args...)}
+}
+
+// commandFailed logs err and shows the user message
+func commandFailed(message string, err error) error {
+	return &commandError{message: message, err: err}
+}
+
+// optionDefinitions generates command options from the tagged fields of an options
+// struct. Discord requires required options to come first, so they are moved ahead
+// of optional ones; otherwise field order is kept.
+func optionDefinitions(t reflect.Type) []*discordgo.ApplicationCommandOption {
+	var required, optional []*discordgo.ApplicationCommandOption
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		name := field.Tag.Get("option")
+		if name == "" {
+			continue
+		}
+
+		option := &discordgo.ApplicationCommandOption{
+			Type:        optionType(field.Type),
+			Name:        name,
+			Description: field.Tag.Get("description"),
+			Required:    field.Tag.Get("required") == "true",
+		}
+		if set := field.Tag.Get("choices"); set != "" {
+			option.Choices = optionChoiceSets[set]()
+		}
+		if field.Tag.Get("autocomplete") != "" {
+			option.Autocomplete = true
+		}
+		if minValue, err := strconv.ParseFloat(field.Tag.Get("min"), 64); err == nil {
+			option.MinValue = &minValue
+		}
+		if maxValue, err := strconv.ParseFloat(field.Tag.Get("max"), 64); err == nil {
+			option.MaxValue = maxValue
+		}
+
+		if option.Required {
+			required = append(required, option)
+		} else {
+			optional = append(optional, option)
+		}
+	}
+	return append(required, optional...)
+}
+
+// optionType maps a Go field type to its Discord option type
+func optionType(t reflect.Type) discordgo.ApplicationCommandOptionType {
+	switch {
+	case t == reflect.TypeFor[*discordgo.User]():
+		return discordgo.ApplicationCommandOptionUser
+	case t.Kind() == reflect.String:
+		return discordgo.ApplicationCommandOptionString
+	case t.Kind() == reflect.Int:
+		return discordgo.ApplicationCommandOptionInteger
+	case t.Kind() == reflect.Float64:
+		return discordgo.ApplicationCommandOptionNumber
+	case t.Kind() == reflect.Bool, t == reflect.TypeFor[*bool]():
+		return discordgo.ApplicationCommandOptionBoolean
+	}
+	panic(fmt.Sprintf("unsupported command option type %s", t))
+}
+
+// bindOptions copies the options the user gave into the tagged fields of target,
+// applying defaults and checking required options, choices and bounds
+func bindOptions(i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, target any) error {
+	given := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, option := range options {
+		given[option.Name] = option
+	}
+
+	value := reflect.ValueOf(target).Elem()
+	for idx := 0; idx < value.NumField(); idx++ {
+		field := value.Type().Field(idx)
+		name := field.Tag.Get("option")
+		if name == "" {
+			continue
+		}
+
+		option, ok := given[name]
+		if !ok {
+			if field.Tag.Get("required") == "true" {
+				return userError("❌ The `%s` option is required.", name)
+			}
+			err := setDefaultOption(value.Field(idx), field.Tag.Get("default"))
+			if err != nil {
+				return fmt.Errorf("bad default for option %s: %w", name, err)
+			}
+			continue
+		}
+
+		err := setOption(i, value.Field(idx), option)
+		if err != nil {
+			return err
+		}
+		err = checkOption(value.Field(idx), field)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setOption stores one option value in a field
+func setOption(i *discordgo.InteractionCreate, field reflect.Value, option *discordgo.ApplicationCommandInteractionDataOption) error {
+	switch {
+	case field.Type() == reflect.TypeFor[*discordgo.User]():
+		field.Set(reflect.ValueOf(resolveUserOption(i, option)))
+	case field.Kind() == reflect.String:
+		field.SetString(strings.TrimSpace(option.StringValue()))
+	case field.Kind() == reflect.Int:
+		field.SetInt(option.IntValue())
+	case field.Kind() == reflect.Float64:
+		field.SetFloat(option.FloatValue())
+	case field.Kind() == reflect.Bool:
+		field.SetBool(option.BoolValue())
+	case field.Type() == reflect.TypeFor[*bool]():
+		value := option.BoolValue()
+		field.Set(reflect.ValueOf(&value))
+	default:
+		return fmt.Errorf("unsupported command option type %s", field.Type())
+	}
+	return nil
+}
+
+// setDefaultOption stores a field's default tag value, if it has one
+func setDefaultOption(field reflect.Value, raw string) error {
+	if raw == "" {
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(value))
+	case reflect.Float64:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(value)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(value)
+	}
+	return nil
+}
+
+// checkOption enforces a bound field's choices and min/max tags. Discord checks these
+// too, but definitions can be out of sync with the running code during a deploy.
+func checkOption(field reflect.Value, structField reflect.StructField) error {
+	name := structField.Tag.Get("option")
+
+	if set := structField.Tag.Get("choices"); set != "" {
+		for _, choice := range optionChoiceSets[set]() {
+			if canonical, ok := choice.Value.(string); ok && strings.EqualFold(canonical, field.String()) {
+				field.SetString(canonical)
+				return nil
+			}
+		}
+		return userError("❌ `%s` isn't a valid %s.", field.String(), name)
+	}
+
+	var number float64
+	switch field.Kind() {
+	case reflect.Int:
+		number = float64(field.Int())
+	case reflect.Float64:
+		number = field.Float()
+	default:
+		return nil
+	}
+	if minValue, err := strconv.ParseFloat(structField.Tag.Get("min"), 64); err == nil && number < minValue {
+		return userError("❌ `%s` must be at least %g.", name, minValue)
+	}
+	if maxValue, err := strconv.ParseFloat(structField.Tag.Get("max"), 64); err == nil && number > maxValue {
+		return userError("❌ `%s` must be at most %g.", name, maxValue)
+	}
+	return nil
+}
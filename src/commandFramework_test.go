@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type bindTestOptions struct {
+	Leader  string          `option:"leader" description:"Leader" required:"true"`
+	Outcome string          `option:"outcome" description:"Outcome" choices:"outcome"`
+	Days    int             `option:"days" description:"Days" default:"30" min:"1" max:"365"`
+	Rate    float64         `option:"rate" description:"Rate" max:"1"`
+	Private bool            `option:"private" description:"Private" default:"true"`
+	First   *bool           `option:"first" description:"Went first"`
+	Player  *discordgo.User `option:"player" description:"Player" autocomplete:"player"`
+	Ignored string
+}
+
+func stringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+}
+
+func intOption(name string, value int) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionInteger, Value: float64(value)}
+}
+
+func TestBindOptions(t *testing.T) {
+	interaction := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type: discordgo.InteractionApplicationCommand,
+		Data: discordgo.ApplicationCommandInteractionData{
+			Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+				Users: map[string]*discordgo.User{"42": {ID: "42", Username: "nami"}},
+			},
+		},
+	}}
+	yes := true
+
+	tests := []struct {
+		name    string
+		options []*discordgo.ApplicationCommandInteractionDataOption
+		want    bindTestOptions
+		wantErr string
+	}{
+		{
+			name:    "defaults fill in left out options",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("leader", "  Luffy  ")},
+			want:    bindTestOptions{Leader: "Luffy", Days: 30, Private: true},
+		},
+		{
+			name: "every option type is bound",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{
+				stringOption("leader", "Luffy"),
+				stringOption("outcome", "WIN"),
+				intOption("days", 7),
+				{Name: "rate", Type: discordgo.ApplicationCommandOptionNumber, Value: 0.25},
+				{Name: "private", Type: discordgo.ApplicationCommandOptionBoolean, Value: false},
+				{Name: "first", Type: discordgo.ApplicationCommandOptionBoolean, Value: true},
+				{Name: "player", Type: discordgo.ApplicationCommandOptionUser, Value: "42"},
+			},
+			want: bindTestOptions{
+				Leader:  "Luffy",
+				Outcome: "win",
+				Days:    7,
+				Rate:    0.25,
+				First:   &yes,
+				Player:  &discordgo.User{ID: "42", Username: "nami"},
+			},
+		},
+		{
+			name:    "unresolved users keep their ID",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("leader", "Luffy"), {Name: "player", Type: discordgo.ApplicationCommandOptionUser, Value: "7"}},
+			want:    bindTestOptions{Leader: "Luffy", Days: 30, Private: true, Player: &discordgo.User{ID: "7"}},
+		},
+		{
+			name:    "missing required option",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{intOption("days", 7)},
+			wantErr: "❌ The `leader` option is required.",
+		},
+		{
+			name:    "value outside the choices",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("leader", "Luffy"), stringOption("outcome", "tie")},
+			wantErr: "❌ `tie` isn't a valid outcome.",
+		},
+		{
+			name:    "below min",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("leader", "Luffy"), intOption("days", 0)},
+			wantErr: "❌ `days` must be at least 1.",
+		},
+		{
+			name:    "above max",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("leader", "Luffy"), {Name: "rate", Type: discordgo.ApplicationCommandOptionNumber, Value: 1.5}},
+			wantErr: "❌ `rate` must be at most 1.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bindTestOptions
+			err := bindOptions(interaction, tt.options, &got)
+
+			if tt.wantErr != "" {
+				var cmdErr *commandError
+				if !errors.As(err, &cmdErr) || cmdErr.message != tt.wantErr {
+					t.Fatalf("bindOptions() error = %v, want user error %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bindOptions() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bindOptions() bound %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionDefinitions(t *testing.T) {
+	minDays := 1.0
+	definitions := optionDefinitions(reflect.TypeFor[bindTestOptions]())
+
+	tests := []struct {
+		name string
+		want *discordgo.ApplicationCommandOption
+	}{
+		{"leader", &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionString, Name: "leader", Description: "Leader", Required: true}},
+		{"outcome", &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionString, Name: "outcome", Description: "Outcome", Choices: outcomeChoices()}},
+		{"days", &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionInteger, Name: "days", Description: "Days", MinValue: &minDays, MaxValue: 365}},
+		{"rate", &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionNumber, Name: "rate", Description: "Rate", MaxValue: 1}},
+		{"private", &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionBoolean, Name: "private", Description: "Private"}},
+		{"first", &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionBoolean, Name: "first", Description: "Went first"}},
+		{"player", &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionUser, Name: "player", Description: "Player", Autocomplete: true}},
+	}
+
+	if len(definitions) != len(tests) {
+		t.Fatalf("optionDefinitions() returned %d options, want %d", len(definitions), len(tests))
+	}
+	for idx, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(definitions[idx], tt.want) {
+				t.Errorf("option %d = %+v, want %+v", idx, definitions[idx], tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionDefinitionsPutRequiredFirst(t *testing.T) {
+	type options struct {
+		Category string `option:"category" description:"Category"`
+		Leader   string `option:"leader" description:"Leader" required:"true"`
+		Days     int    `option:"days" description:"Days"`
+		Opponent string `option:"opponent" description:"Opponent" required:"true"`
+	}
+
+	var names []string
+	for _, option := range optionDefinitions(reflect.TypeFor[options]()) {
+		names = append(names, option.Name)
+	}
+	want := []string{"leader", "opponent", "category", "days"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("option order = %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// commandChange is a single step of a command sync
+type commandChange struct {
+	action  string // "create", "update" or "delete"
+	name    string
+	details []string
+	want    *discordgo.ApplicationCommand
+	have    *discordgo.ApplicationCommand
+}
+
+func (c commandChange) String() string {
+	symbol := map[string]string{"create": "+", "update": "~", "delete": "-"}[c.action]
+	line := fmt.Sprintf("%s %s /%s", symbol, c.action, c.name)
+	if len(c.details) > 0 {
+		line += " (" + strings.Join(c.details, ", ") + ")"
+	}
+	return line
+}
+
+// syncCommands brings the registered commands in line with the definitions, only
+// creating, updating or deleting the ones that changed. In dry-run mode it logs the
+// diff without applying it. It returns the commands registered afterwards.
+func syncCommands(discord *discordgo.Session, guildID string, definitions []*discordgo.ApplicationCommand, dryRun bool) ([]*discordgo.ApplicationCommand, error) {
+	appID := discord.State.User.ID
+
+	registered, err := discord.ApplicationCommands(appID, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registered commands: %w", err)
+	}
+
+	changes, unchanged := diffCommands(definitions, registered)
+
+	scope := "globally"
+	if guildID != "" {
+		scope = "in guild " + guildID
+	}
+	slog.Info("Command sync planned", "scope", scope, "unchanged", len(unchanged), "changes", len(changes))
+	for _, change := range changes {
+		slog.Info("Command change", "change", change)
+	}
+
+	if dryRun {
+		slog.Info("Dry run: no commands were changed")
+		return registered, nil
+	}
+
+	current := unchanged
+	for _, change := range changes {
+		switch change.action {
+		case "create":
+			cmd, err := discord.ApplicationCommandCreate(appID, guildID, change.want)
+			if err != nil {
+				return current, fmt.Errorf("failed to create command '%s': %w", change.name, err)
+			}
+			current = append(current, cmd)
+		case "update":
+			cmd, err := discord.ApplicationCommandEdit(appID, guildID, change.have.ID, change.want)
+			if err != nil {
+				return current, fmt.Errorf("failed to update command '%s': %w", change.name, err)
+			}
+			current = append(current, cmd)
+		case "delete":
+			err := discord.ApplicationCommandDelete(appID, guildID, change.have.ID)
+			if err != nil {
+				return current, fmt.Errorf("failed to delete command '%s': %w", change.name, err)
+			}
+		}
+	}
+
+	return current, nil
+}
+
+// diffCommands compares the wanted definitions with the registered commands. It
+// returns the changes needed and the registered commands that can stay as they are.
+func diffCommands(definitions, registered []*discordgo.ApplicationCommand) ([]commandChange, []*discordgo.ApplicationCommand) {
+	registeredByName := map[string]*discordgo.ApplicationCommand{}
+	for _, cmd := range registered {
+		registeredByName[commandKey(cmd)] = cmd
+	}
+
+	var changes []commandChange
+	var unchanged []*discordgo.ApplicationCommand
+	for _, want := range definitions {
+		have, ok := registeredByName[commandKey(want)]
+		if !ok {
+			changes = append(changes, commandChange{action: "create", name: want.Name, want: want})
+			continue
+		}
+		delete(registeredByName, commandKey(want))
+
+		details := commandDifferences(want, have)
+		if len(details) == 0 {
+			unchanged = append(unchanged, have)
+			continue
+		}
+		changes = append(changes, commandChange{action: "update", name: want.Name, details: details, want: want, have: have})
+	}
+
+	for _, cmd := range registered {
+		if _, stale := registeredByName[commandKey(cmd)]; stale {
+			changes = append(changes, commandChange{action: "delete", name: cmd.Name, have: cmd})
+		}
+	}
+
+	return changes, unchanged
+}
+
+// commandKey identifies a command by type and name; chat commands and context menu
+// commands may share a name
+func commandKey(cmd *discordgo.ApplicationCommand) string {
+	cmdType := cmd.Type
+	if cmdType == 0 {
+		cmdType = discordgo.ChatApplicationCommand
+	}
+	return fmt.Sprintf("%d:%s", cmdType, cmd.Name)
+}
+
+// commandDifferences lists the fields where a registered command differs from its
+// definition. Optional fields the definition leaves unset are not compared, since
+// Discord fills them in with its own defaults.
+func commandDifferences(want, have *discordgo.ApplicationCommand) []string {
+	var details []string
+
+	if want.Description != have.Description {
+		details = append(details, "description")
+	}
+	if permissionsString(want.DefaultMemberPermissions) != permissionsString(have.DefaultMemberPermissions) {
+		details = append(details, "default_member_permissions")
+	}
+	if want.NSFW != nil && (have.NSFW == nil || *want.NSFW != *have.NSFW) {
+		details = append(details, "nsfw")
+	}
+	if want.Contexts != nil && (have.Contexts == nil || !reflect.DeepEqual(*want.Contexts, *have.Contexts)) {
+		details = append(details, "contexts")
+	}
+	if want.IntegrationTypes != nil && (have.IntegrationTypes == nil || !reflect.DeepEqual(*want.IntegrationTypes, *have.IntegrationTypes)) {
+		details = append(details, "integration_types")
+	}
+	if optionsFingerprint(want.Options) != optionsFingerprint(have.Options) {
+		details = append(details, "options")
+	}
+
+	return details
+}
+
+// permissionsString renders optional default member permissions for comparison
+func permissionsString(permissions *int64) string {
+	if permissions == nil {
+		return ""
+	}
+	return fmt.Sprint(*permissions)
+}
+
+// optionShape is the part of a command option that matters for diffing
+type optionShape struct {
+	Type         discordgo.ApplicationCommandOptionType `json:"type"`
+	Name         string                                 `json:"name"`
+	Description  string                                 `json:"description"`
+	Required     bool                                   `json:"required"`
+	Autocomplete bool                                   `json:"autocomplete"`
+	Choices      [][2]string                            `json:"choices"`
+	ChannelTypes []discordgo.ChannelType                `json:"channel_types"`
+	MinValue     string                                 `json:"min_value"`
+	MaxValue     float64                                `json:"max_value"`
+	MinLength    string                                 `json:"min_length"`
+	MaxLength    int                                    `json:"max_length"`
+	Options      []optionShape                          `json:"options"`
+}
+
+// optionsFingerprint renders options in a canonical form so a definition and the
+// same command read back from Discord compare equal
+func optionsFingerprint(options []*discordgo.ApplicationCommandOption) string {
+	encoded, err := json.Marshal(optionShapes(options))
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+func optionShapes(options []*discordgo.ApplicationCommandOption) []optionShape {
+	var shapes []optionShape
+	for _, option := range options {
+		shape := optionShape{
+			Type:         option.Type,
+			Name:         option.Name,
+			Description:  option.Description,
+			Required:     option.Required,
+			Autocomplete: option.Autocomplete,
+			ChannelTypes: option.ChannelTypes,
+			MaxValue:     option.MaxValue,
+			MaxLength:    option.MaxLength,
+			Options:      optionShapes(option.Options),
+		}
+		// Choice values come back from Discord as JSON numbers or strings
+		for _, choice := range option.Choices {
+			shape.Choices = append(shape.Choices, [2]string{choice.Name, fmt.Sprint(choice.Value)})
+		}
+		if option.MinValue != nil {
+			shape.MinValue = fmt.Sprint(*option.MinValue)
+		}
+		if option.MinLength != nil {
+			shape.MinLength = fmt.Sprint(*option.MinLength)
+		}
+		shapes = append(shapes, shape)
+	}
+	return shapes
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestDiffCommands(t *testing.T) {
+	adminOnly := int64(discordgo.PermissionAdministrator)
+	guildOnly := []discordgo.InteractionContextType{discordgo.InteractionContextGuild}
+
+	option := func(name, description string, required bool) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        name,
+			Description: description,
+			Required:    required,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		definitions   []*discordgo.ApplicationCommand
+		registered    []*discordgo.ApplicationCommand
+		wantChanges   []string
+		wantUnchanged []string
+	}{
+		{
+			name:        "nothing registered yet",
+			definitions: []*discordgo.ApplicationCommand{{Name: "stats", Description: "Show stats"}},
+			wantChanges: []string{"+ create /stats"},
+		},
+		{
+			name:          "identical commands are left alone",
+			definitions:   []*discordgo.ApplicationCommand{{Name: "stats", Description: "Show stats", Options: []*discordgo.ApplicationCommandOption{option("category", "Category", false)}}},
+			registered:    []*discordgo.ApplicationCommand{{ID: "1", Name: "stats", Description: "Show stats", Options: []*discordgo.ApplicationCommandOption{option("category", "Category", false)}}},
+			wantUnchanged: []string{"stats"},
+		},
+		{
+			name:        "changed fields are listed",
+			definitions: []*discordgo.ApplicationCommand{{Name: "audit", Description: "Read the audit log", DefaultMemberPermissions: &adminOnly, Options: []*discordgo.ApplicationCommandOption{option("member", "Member", true)}}},
+			registered:  []*discordgo.ApplicationCommand{{ID: "1", Name: "audit", Description: "Audit log", Options: []*discordgo.ApplicationCommandOption{option("member", "Member", false)}}},
+			wantChanges: []string{"~ update /audit (description, default_member_permissions, options)"},
+		},
+		{
+			name:        "contexts are compared when the definition sets them",
+			definitions: []*discordgo.ApplicationCommand{{Name: "meta", Description: "Meta", Contexts: &guildOnly}},
+			registered:  []*discordgo.ApplicationCommand{{ID: "1", Name: "meta", Description: "Meta"}},
+			wantChanges: []string{"~ update /meta (contexts)"},
+		},
+		{
+			name:          "fields the definition leaves unset are ignored",
+			definitions:   []*discordgo.ApplicationCommand{{Name: "meta", Description: "Meta"}},
+			registered:    []*discordgo.ApplicationCommand{{ID: "1", Name: "meta", Description: "Meta", Contexts: &guildOnly}},
+			wantUnchanged: []string{"meta"},
+		},
+		{
+			name:        "stale commands are deleted",
+			definitions: []*discordgo.ApplicationCommand{{Name: "stats", Description: "Show stats"}},
+			registered: []*discordgo.ApplicationCommand{
+				{ID: "1", Name: "stats", Description: "Show stats"},
+				{ID: "2", Name: "leaderboard", Description: "Old leaderboard"},
+			},
+			wantChanges:   []string{"- delete /leaderboard"},
+			wantUnchanged: []string{"stats"},
+		},
+		{
+			name:        "context menu command with a chat command's name is separate",
+			definitions: []*discordgo.ApplicationCommand{{Name: "stats", Description: "Show stats"}},
+			registered: []*discordgo.ApplicationCommand{
+				{ID: "1", Name: "stats", Type: discordgo.UserApplicationCommand},
+			},
+			wantChanges: []string{"+ create /stats", "- delete /stats"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, unchanged := diffCommands(tt.definitions, tt.registered)
+
+			var gotChanges []string
+			for _, change := range changes {
+				gotChanges = append(gotChanges, change.String())
+			}
+			if !reflect.DeepEqual(gotChanges, tt.wantChanges) {
+				t.Errorf("changes = %q, want %q", gotChanges, tt.wantChanges)
+			}
+
+			var gotUnchanged []string
+			for _, cmd := range unchanged {
+				gotUnchanged = append(gotUnchanged, cmd.Name)
+			}
+			if !reflect.DeepEqual(gotUnchanged, tt.wantUnchanged) {
+				t.Errorf("unchanged = %q, want %q", gotUnchanged, tt.wantUnchanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Config holds every setting the bot reads at startup. Each field is loaded from the
+// environment variable in its `env` tag, after an optional .env file is applied.
+// Fields with a `flag` tag can also be set on the command line, which wins over both.
+//
+// Supported tags:
+//   - default:  value used when the variable is unset
+//   - required: "true" if startup must fail without a value
+//   - secret:   "true" to redact the value when the config is logged
+//   - validate: "snowflake", "url", "port", "positive", "loglevel" or "oneof:a|b"
+type Config struct {
+	DiscordToken string `env:"DISCORD_TOKEN" required:"true" secret:"true"`
+	GuildID      string `env:"GUILD_ID" flag:"guild" validate:"snowflake" usage:"Test guild ID. If not passed - bot registers commands globally"`
+	CategoryID   string `env:"CATEGORY_ID" validate:"snowflake" usage:"Channel category that /create-game channels are created in"`
+
+	GameArchiveAfterHours int `env:"GAME_ARCHIVE_AFTER_HOURS" default:"168" validate:"positive"`
+
+	// DatabaseURL is a full Postgres DSN. When set, the POSTGRES_* settings are ignored.
+	DatabaseURL         string `env:"DATABASE_URL" secret:"true"`
+	PostgresHost        string `env:"POSTGRES_HOST" default:"localhost"`
+	PostgresPort        string `env:"POSTGRES_PORT" default:"5432" validate:"port"`
+	PostgresUser        string `env:"POSTGRES_USER"`
+	PostgresPassword    string `env:"POSTGRES_PASSWORD" secret:"true"`
+	PostgresDB          string `env:"POSTGRES_DB"`
+	PostgresSSLMode     string `env:"POSTGRES_SSLMODE" default:"disable" validate:"oneof:disable|require|verify-ca|verify-full"`
+	PostgresSSLRootCert string `env:"POSTGRES_SSLROOTCERT"`
+
+	DBMaxRetries        int `env:"DB_MAX_RETRIES" default:"5" validate:"positive"`
+	DBRetryDelaySeconds int `env:"DB_RETRY_DELAY_SECONDS" default:"10" validate:"positive"`
+
+	DBMaxOpenConns               int `env:"DB_MAX_OPEN_CONNS" default:"10" validate:"positive"`
+	DBMaxIdleConns               int `env:"DB_MAX_IDLE_CONNS" default:"5" validate:"positive"`
+	DBConnMaxLifetimeSeconds     int `env:"DB_CONN_MAX_LIFETIME_SECONDS" default:"1800" validate:"positive"`
+	DBConnMaxIdleTimeSeconds     int `env:"DB_CONN_MAX_IDLE_TIME_SECONDS" default:"300" validate:"positive"`
+	DBHealthCheckIntervalSeconds int `env:"DB_HEALTH_CHECK_INTERVAL_SECONDS" default:"15" validate:"positive"`
+
+	HTTPPort         string `env:"HTTP_PORT" default:"8080" validate:"port"`
+	DashboardBaseURL string `env:"DASHBOARD_BASE_URL" validate:"url"`
+
+	CommandSyncDryRun      bool `env:"COMMAND_SYNC_DRY_RUN" flag:"dry-run-commands" usage:"Log the slash command diff and exit without applying it"`
+	ShutdownTimeoutSeconds int  `env:"SHUTDOWN_TIMEOUT_SECONDS" default:"20" validate:"positive"`
+
+	InteractionTimeoutSeconds int  `env:"INTERACTION_TIMEOUT_SECONDS" default:"10" validate:"positive"`
+	HTTPRequestTimeoutSeconds int  `env:"HTTP_REQUEST_TIMEOUT_SECONDS" default:"10" validate:"positive"`
+	RemoveCommandsOnExit      bool `env:"REMOVE_COMMANDS_ON_EXIT"`
+
+	LogFormat string `env:"LOG_FORMAT" default:"text" validate:"oneof:text|json"`
+	LogLevel  string `env:"LOG_LEVEL" default:"info" validate:"loglevel"`
+}
+
+// AppConfig is the configuration loaded at startup
+var AppConfig = &Config{}
+
+// DBRetryDelay is the wait between database connection attempts at startup
+func (c *Config) DBRetryDelay() time.Duration {
+	return time.Duration(c.DBRetryDelaySeconds) * time.Second
+}
+
+// ShutdownTimeout is how long shutdown waits for in-flight interactions
+func (c *Config) ShutdownTimeout() time.Duration {
+	return time.Duration(c.ShutdownTimeoutSeconds) * time.Second
+}
+
+// DBConnMaxLifetime is how long a pooled database connection is reused before it is replaced
+func (c *Config) DBConnMaxLifetime() time.Duration {
+	return time.Duration(c.DBConnMaxLifetimeSeconds) * time.Second
+}
+
+// DBConnMaxIdleTime is how long a pooled database connection may sit idle before it is closed
+func (c *Config) DBConnMaxIdleTime() time.Duration {
+	return time.Duration(c.DBConnMaxIdleTimeSeconds) * time.Second
+}
+
+// DBHealthCheckInterval is the time between background database pings
+func (c *Config) DBHealthCheckInterval() time.Duration {
+	return time.Duration(c.DBHealthCheckIntervalSeconds) * time.Second
+}
+
+// GameArchiveAfter is how long a /create-game channel can go without activity before
+// it is archived
+func (c *Config) GameArchiveAfter() time.Duration {
+	return time.Duration(c.GameArchiveAfterHours) * time.Hour
+}
+
+// InteractionTimeout bounds the database work done for a single Discord interaction
+func (c *Config) InteractionTimeout() time.Duration {
+	return time.Duration(c.InteractionTimeoutSeconds) * time.Second
+}
+
+// HTTPRequestTimeout bounds the database work done for a single API or dashboard request
+func (c *Config) HTTPRequestTimeout() time.Duration {
+	return time.Duration(c.HTTPRequestTimeoutSeconds) * time.Second
+}
+
+// LoadConfig builds the configuration from defaults, an optional .env file, the
+// environment and command line flags, in increasing order of precedence, then
+// validates it. Variables already set in the environment are not overridden by .env.
+func LoadConfig(args []string) (*Config, error) {
+	config := &Config{}
+	configValue := reflect.ValueOf(config).Elem()
+	configType := configValue.Type()
+
+	flags := flag.NewFlagSet("accountability-optcg", flag.ContinueOnError)
+	envFile := flags.String("env-file", ".env", "Optional file of KEY=value settings")
+	flagValues := map[string]*string{}
+	for idx := 0; idx < configType.NumField(); idx++ {
+		field := configType.Field(idx)
+		if name := field.Tag.Get("flag"); name != "" {
+			flagValues[field.Name] = flags.String(name, "", field.Tag.Get("usage"))
+		}
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	err = godotenv.Load(*envFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load %s: %w", *envFile, err)
+	}
+
+	var problems []string
+	for idx := 0; idx < configType.NumField(); idx++ {
+		field := configType.Field(idx)
+		env := field.Tag.Get("env")
+
+		raw, set := os.LookupEnv(env)
+		raw = strings.TrimSpace(raw)
+		if flagValue, ok := flagValues[field.Name]; ok && isFlagSet(flags, field.Tag.Get("flag")) {
+			raw, set = strings.TrimSpace(*flagValue), true
+		}
+		if !set || raw == "" {
+			raw = field.Tag.Get("default")
+		}
+
+		if raw == "" {
+			if field.Tag.Get("required") == "true" {
+				problems = append(problems, fmt.Sprintf("%s is required", env))
+			}
+			continue
+		}
+
+		err = setConfigField(configValue.Field(idx), raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", env, err))
+			continue
+		}
+
+		err = validateConfigField(field.Tag.Get("validate"), configValue.Field(idx))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", env, err))
+		}
+	}
+
+	problems = append(problems, config.crossFieldProblems()...)
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	return config, nil
+}
+
+// crossFieldProblems checks rules that involve more than one setting
+func (c *Config) crossFieldProblems() []string {
+	var problems []string
+	if c.DatabaseURL == "" {
+		if c.PostgresUser == "" {
+			problems = append(problems, "POSTGRES_USER is required unless DATABASE_URL is set")
+		}
+		if c.PostgresDB == "" {
+			problems = append(problems, "POSTGRES_DB is required unless DATABASE_URL is set")
+		}
+	}
+	if c.DBMaxIdleConns > c.DBMaxOpenConns {
+		problems = append(problems, "DB_MAX_IDLE_CONNS must not be greater than DB_MAX_OPEN_CONNS")
+	}
+	return problems
+}
+
+// isFlagSet reports whether a flag was passed on the command line
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// setConfigField parses a raw value into a config field
+func setConfigField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		field.SetInt(int64(value))
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		field.SetBool(value)
+	default:
+		return fmt.Errorf("unsupported config type %s", field.Kind())
+	}
+	return nil
+}
+
+// validateConfigField applies the rule named in a field's validate tag
+func validateConfigField(rule string, field reflect.Value) error {
+	if allowed, ok := strings.CutPrefix(rule, "oneof:"); ok {
+		for _, option := range strings.Split(allowed, "|") {
+			if strings.EqualFold(field.String(), option) {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.ReplaceAll(allowed, "|", ", "))
+	}
+
+	switch rule {
+	case "snowflake":
+		if _, err := strconv.ParseUint(field.String(), 10, 64); err != nil {
+			return fmt.Errorf("must be a Discord ID (digits only)")
+		}
+	case "url":
+		parsed, err := url.Parse(field.String())
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("must be an http(s) URL")
+		}
+	case "port":
+		port, err := strconv.Atoi(field.String())
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("must be a port number")
+		}
+	case "loglevel":
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(field.String())); err != nil {
+			return fmt.Errorf("must be debug, info, warn or error")
+		}
+	case "positive":
+		if field.Int() <= 0 {
+			return fmt.Errorf("must be greater than zero")
+		}
+	}
+	return nil
+}
+
+// LogSummary logs every setting, with secrets redacted
+func (c *Config) LogSummary() {
+	configValue := reflect.ValueOf(c).Elem()
+	configType := configValue.Type()
+
+	attrs := make([]any, 0, configType.NumField())
+	for idx := 0; idx < configType.NumField(); idx++ {
+		field := configType.Field(idx)
+		value := fmt.Sprint(configValue.Field(idx).Interface())
+		switch {
+		case value == "":
+			value = "(unset)"
+		case field.Tag.Get("secret") == "true":
+			value = "[redacted]"
+		}
+		attrs = append(attrs, slog.String(field.Tag.Get("env"), value))
+	}
+	slog.Info("Configuration loaded", attrs...)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setConfigEnv clears every config variable, so the environment running the tests
+// can't leak in, then sets the given ones
+func setConfigEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	configType := reflect.TypeFor[Config]()
+	for idx := 0; idx < configType.NumField(); idx++ {
+		t.Setenv(configType.Field(idx).Tag.Get("env"), "")
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+// noEnvFile points LoadConfig at a .env file that doesn't exist
+func noEnvFile(t *testing.T) string {
+	return "-env-file=" + filepath.Join(t.TempDir(), ".env")
+}
+
+func TestLoadConfig(t *testing.T) {
+	valid := map[string]string{
+		"DISCORD_TOKEN": "token",
+		"POSTGRES_USER": "bot",
+		"POSTGRES_DB":   "optcg",
+	}
+	with := func(changes map[string]string) map[string]string {
+		env := map[string]string{}
+		for key, value := range valid {
+			env[key] = value
+		}
+		for key, value := range changes {
+			env[key] = value
+		}
+		return env
+	}
+
+	tests := []struct {
+		name         string
+		env          map[string]string
+		args         []string
+		check        func(t *testing.T, config *Config)
+		wantProblems []string
+	}{
+		{
+			name: "defaults",
+			env:  valid,
+			check: func(t *testing.T, config *Config) {
+				if config.PostgresHost != "localhost" || config.HTTPPort != "8080" || config.GameArchiveAfterHours != 168 || config.LogLevel != "info" {
+					t.Errorf("defaults not applied: %+v", config)
+				}
+			},
+		},
+		{
+			name: "environment values are parsed",
+			env:  with(map[string]string{"DB_MAX_RETRIES": " 3 ", "REMOVE_COMMANDS_ON_EXIT": "true", "LOG_FORMAT": "JSON"}),
+			check: func(t *testing.T, config *Config) {
+				if config.DBMaxRetries != 3 || !config.RemoveCommandsOnExit || config.LogFormat != "JSON" {
+					t.Errorf("values not parsed: %+v", config)
+				}
+			},
+		},
+		{
+			name: "flags win over the environment",
+			env:  with(map[string]string{"GUILD_ID": "111"}),
+			args: []string{"-guild=222", "-dry-run-commands=true"},
+			check: func(t *testing.T, config *Config) {
+				if config.GuildID != "222" || !config.CommandSyncDryRun {
+					t.Errorf("flags not applied: GuildID=%q CommandSyncDryRun=%v", config.GuildID, config.CommandSyncDryRun)
+				}
+			},
+		},
+		{
+			name: "database URL replaces the postgres settings",
+			env:  map[string]string{"DISCORD_TOKEN": "token", "DATABASE_URL": "postgres://bot@db/optcg"},
+		},
+		{
+			name:         "missing required settings",
+			env:          map[string]string{},
+			wantProblems: []string{"DISCORD_TOKEN is required", "POSTGRES_USER is required unless DATABASE_URL is set", "POSTGRES_DB is required unless DATABASE_URL is set"},
+		},
+		{
+			name: "invalid values are all reported",
+			env: with(map[string]string{
+				"GUILD_ID":             "not-an-id",
+				"POSTGRES_PORT":        "70000",
+				"POSTGRES_SSLMODE":     "sometimes",
+				"DB_MAX_RETRIES":       "five",
+				"DB_MAX_OPEN_CONNS":    "0",
+				"DASHBOARD_BASE_URL":   "ftp://example.com",
+				"LOG_LEVEL":            "loud",
+				"COMMAND_SYNC_DRY_RUN": "maybe",
+			}),
+			wantProblems: []string{
+				"GUILD_ID: must be a Discord ID (digits only)",
+				"POSTGRES_PORT: must be a port number",
+				"POSTGRES_SSLMODE: must be one of disable, require, verify-ca, verify-full",
+				"DB_MAX_RETRIES: must be a whole number",
+				"DB_MAX_OPEN_CONNS: must be greater than zero",
+				"DASHBOARD_BASE_URL: must be an http(s) URL",
+				"COMMAND_SYNC_DRY_RUN: must be true or false",
+				"LOG_LEVEL: must be debug, info, warn or error",
+			},
+		},
+		{
+			name:         "idle connections above the pool size",
+			env:          with(map[string]string{"DB_MAX_OPEN_CONNS": "2", "DB_MAX_IDLE_CONNS": "3"}),
+			wantProblems: []string{"DB_MAX_IDLE_CONNS must not be greater than DB_MAX_OPEN_CONNS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigEnv(t, tt.env)
+
+			config, err := LoadConfig(append([]string{noEnvFile(t)}, tt.args...))
+			if len(tt.wantProblems) > 0 {
+				if err == nil {
+					t.Fatalf("LoadConfig() succeeded, want problems %q", tt.wantProblems)
+				}
+				for _, problem := range tt.wantProblems {
+					if !strings.Contains(err.Error(), problem) {
+						t.Errorf("LoadConfig() error = %q, missing %q", err, problem)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, config)
+			}
+		})
+	}
+}
+
+func TestConfigLogSummaryRedactsSecrets(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	config := &Config{
+		DiscordToken:     "discord-secret",
+		DatabaseURL:      "postgres://bot:db-secret@db/optcg",
+		PostgresPassword: "",
+		PostgresHost:     "db",
+		HTTPPort:         "8080",
+	}
+	config.LogSummary()
+
+	var entry map[string]any
+	err := json.Unmarshal(logs.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("failed to decode log line %q: %v", logs.String(), err)
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"DISCORD_TOKEN", "[redacted]"},
+		{"DATABASE_URL", "[redacted]"},
+		{"POSTGRES_PASSWORD", "(unset)"},
+		{"POSTGRES_HOST", "db"},
+		{"HTTP_PORT", "8080"},
+		{"GUILD_ID", "(unset)"},
+	}
+	for _, tt := range tests {
+		if entry[tt.key] != tt.want {
+			t.Errorf("%s = %v, want %q", tt.key, entry[tt.key], tt.want)
+		}
+	}
+	if strings.Contains(logs.String(), "secret") {
+		t.Errorf("summary leaks a secret: %s", logs.String())
+	}
+}
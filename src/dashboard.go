@@ -0,0 +1,442 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// dashboardSessionCookie holds the dashboard session token
+	dashboardSessionCookie = "dashboard_session"
+	// dashboardLoginTTL is how long a /dashboard link stays valid
+	dashboardLoginTTL = 15 * time.Minute
+	// dashboardSessionTTL is how long a dashboard login lasts
+	dashboardSessionTTL = 7 * 24 * time.Hour
+	// dashboardMaxGames is the number of games listed on the history page
+	dashboardMaxGames = 100
+	// dashboardLeaderboardSize is the number of members on each leaderboard
+	dashboardLeaderboardSize = 25
+)
+
+//go:embed templates/*.html
+var dashboardTemplateFiles embed.FS
+
+// dashboardTemplates holds one template set per page, each combined with the layout
+var dashboardTemplates = map[string]*template.Template{}
+
+func init() {
+	funcs := template.FuncMap{
+		"inc": func(n int) int { return n + 1 },
+		"percent": func(wins, losses int) string {
+			if wins+losses == 0 {
+				return "–"
+			}
+			return fmt.Sprintf("%.1f%%", winRatePercent(wins, losses))
+		},
+		"record":   formatRecord,
+		"rating":   formatRating,
+		"practice": formatPracticeMinutes,
+	}
+
+	for _, page := range []string{"history", "matchups", "streak", "leaderboards", "login"} {
+		dashboardTemplates[page] = template.Must(template.New(page).Funcs(funcs).ParseFS(dashboardTemplateFiles,
+			"templates/layout.html", "templates/"+page+".html"))
+	}
+}
+
+// dashboardFilterForm echoes the filter inputs back into the page
+type dashboardFilterForm struct {
+	Category string
+	From     string
+	To       string
+}
+
+// dashboardPage is the data passed to every dashboard template
+type dashboardPage struct {
+	Title       string
+	Active      string
+	User        *User
+	Location    *time.Location
+	ShowFilters bool
+	Filter      dashboardFilterForm
+	Categories  []string
+	// Query is the encoded filter, carried over to navigation and chart links
+	Query string
+	Error string
+	Data  any
+}
+
+// LocalTime formats a timestamp in the viewing user's timezone
+func (p *dashboardPage) LocalTime(t time.Time) string {
+	return t.In(p.Location).Format("Jan 2, 2006 3:04 PM")
+}
+
+// dashboardRequest is an authenticated dashboard request
+type dashboardRequest struct {
+	User    *User
+	GuildID string
+	// Categories are the login server's categories, offered in the category filter
+	Categories []string
+	Filter     StatsFilter
+	Form       dashboardFilterForm
+	Query      string
+	// FilterError is set when the filter inputs were invalid and ignored
+	FilterError string
+}
+
+// registerDashboardRoutes adds the web dashboard pages
+func registerDashboardRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /dashboard/login", dashboardLoginPageHandler)
+	mux.HandleFunc("POST /dashboard/login", dashboardLoginHandler)
+	mux.HandleFunc("POST /dashboard/logout", dashboardLogoutHandler)
+	mux.Handle("GET /dashboard", requireDashboardSession(dashboardHistoryHandler))
+	mux.Handle("GET /dashboard/matchups", requireDashboardSession(dashboardMatchupsHandler))
+	mux.Handle("GET /dashboard/streak", requireDashboardSession(dashboardStreakHandler))
+	mux.Handle("GET /dashboard/leaderboards", requireDashboardSession(dashboardLeaderboardsHandler))
+	mux.Handle("GET /dashboard/charts/win-rate.png", requireDashboardSession(dashboardWinRateChartHandler))
+	mux.Handle("GET /dashboard/charts/matchups.png", requireDashboardSession(dashboardMatchupsChartHandler))
+	mux.Handle("GET /dashboard/charts/calendar.png", requireDashboardSession(dashboardCalendarChartHandler))
+}
+
+// dashboardBaseURL is the public URL the dashboard is reachable at, without a trailing slash
+func dashboardBaseURL() string {
+	return strings.TrimRight(AppConfig.DashboardBaseURL, "/")
+}
+
+// requireDashboardSession authenticates a request with the session cookie and parses
+// the category and date filters
+func requireDashboardSession(next func(http.ResponseWriter, *http.Request, *dashboardRequest)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(dashboardSessionCookie)
+		if err != nil {
+			http.Redirect(w, r, "/dashboard/login", http.StatusSeeOther)
+			return
+		}
+
+		user, guildID, err := GetDashboardSession(r.Context(), cookie.Value)
+		if err != nil {
+			if !errors.Is(err, ErrSessionNotFound) {
+				requestLogger(r).Error("Failed to get dashboard session", "error", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			http.Redirect(w, r, "/dashboard/login", http.StatusSeeOther)
+			return
+		}
+
+		categories, err := GetGuildCategories(r.Context(), guildID)
+		if err != nil {
+			requestLogger(r).Error("Failed to get guild categories", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+		req := &dashboardRequest{
+			User:       user,
+			GuildID:    guildID,
+			Categories: categories,
+			Form: dashboardFilterForm{
+				Category: query.Get("category"),
+				From:     query.Get("from"),
+				To:       query.Get("to"),
+			},
+		}
+
+		req.Filter, err = parseStatsFilter(req.Form.Category, req.Form.From, req.Form.To, userLocation(user))
+		if err != nil {
+			req.FilterError = fmt.Sprintf("Filters ignored: %s.", err)
+			req.Filter = StatsFilter{}
+			req.Form = dashboardFilterForm{}
+		}
+
+		values := url.Values{}
+		for key, value := range map[string]string{"category": req.Form.Category, "from": req.Form.From, "to": req.Form.To} {
+			if value != "" {
+				values.Set(key, value)
+			}
+		}
+		req.Query = values.Encode()
+
+		next(w, r, req)
+	})
+}
+
+// renderDashboard renders a dashboard page
+func renderDashboard(w http.ResponseWriter, page string, data *dashboardPage) {
+	if data.Location == nil {
+		data.Location = time.UTC
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := dashboardTemplates[page].ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		slog.Error("Failed to render dashboard page", "error", err)
+	}
+}
+
+// newDashboardPage fills in the fields shared by every logged-in page
+func newDashboardPage(req *dashboardRequest, title, active string, data any) *dashboardPage {
+	return &dashboardPage{
+		Title:       title,
+		Active:      active,
+		User:        req.User,
+		Location:    userLocation(req.User),
+		ShowFilters: true,
+		Filter:      req.Form,
+		Categories:  req.Categories,
+		Query:       req.Query,
+		Error:       req.FilterError,
+		Data:        data,
+	}
+}
+
+// dashboardLoginPageHandler asks for confirmation before using a login link, so link
+// previews and prefetchers can't use up the one-time token
+func dashboardLoginPageHandler(w http.ResponseWriter, r *http.Request) {
+	renderDashboard(w, "login", &dashboardPage{Title: "Log in", Data: r.URL.Query().Get("token")})
+}
+
+func dashboardLoginHandler(w http.ResponseWriter, r *http.Request) {
+	sessionToken, err := ExchangeDashboardLoginToken(r.Context(), r.PostFormValue("token"), dashboardSessionTTL)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidLoginToken) {
+			requestLogger(r).Error("Failed to exchange dashboard login token", "error", err)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		renderDashboard(w, "login", &dashboardPage{
+			Title: "Log in",
+			Error: "This login link has expired or was already used. Run /dashboard again for a new one.",
+		})
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     dashboardSessionCookie,
+		Value:    sessionToken,
+		Path:     "/dashboard",
+		MaxAge:   int(dashboardSessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(dashboardBaseURL(), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+}
+
+func dashboardLogoutHandler(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(dashboardSessionCookie)
+	if err == nil {
+		err = DeleteDashboardSession(r.Context(), cookie.Value)
+		if err != nil {
+			requestLogger(r).Error("Failed to delete dashboard session", "error", err)
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     dashboardSessionCookie,
+		Value:    "",
+		Path:     "/dashboard",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	http.Redirect(w, r, "/dashboard/login", http.StatusSeeOther)
+}
+
+func dashboardHistoryHandler(w http.ResponseWriter, r *http.Request, req *dashboardRequest) {
+	summary, err := GetUserSummary(r.Context(), req.User.ID, req.Filter)
+	if err != nil {
+		requestLogger(r).Error("Failed to get user summary", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	// Fetch one extra game to know whether there are more
+	games, err := GetUserGameResults(r.Context(), req.User.ID, req.Filter, dashboardMaxGames+1, 0)
+	if err != nil {
+		requestLogger(r).Error("Failed to get user game results", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	moreGames := len(games) > dashboardMaxGames
+	if moreGames {
+		games = games[:dashboardMaxGames]
+	}
+
+	renderDashboard(w, "history", newDashboardPage(req, "History", "history", map[string]any{
+		"Summary":   summary,
+		"Games":     games,
+		"MoreGames": moreGames,
+	}))
+}
+
+func dashboardMatchupsHandler(w http.ResponseWriter, r *http.Request, req *dashboardRequest) {
+	matchups, err := GetUserMatchups(r.Context(), req.User.ID, req.Filter)
+	if err != nil {
+		requestLogger(r).Error("Failed to get user matchups", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	renderDashboard(w, "matchups", newDashboardPage(req, "Matchups", "matchups", matchups))
+}
+
+func dashboardStreakHandler(w http.ResponseWriter, r *http.Request, req *dashboardRequest) {
+	loc := userLocation(req.User)
+	days, err := GetUserDailyResults(r.Context(), req.User.ID, req.Filter, loc.String())
+	if err != nil {
+		requestLogger(r).Error("Failed to get daily results", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	days, err = streakDays(r.Context(), req.User.ID, days, req.Filter, loc.String())
+	if err != nil {
+		requestLogger(r).Error("Failed to get session days", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now().In(loc)
+	practice, err := GetUserPracticeTime(r.Context(), req.User.ID, startOfWeek(now), time.Time{})
+	if err != nil {
+		requestLogger(r).Error("Failed to get practice time", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	renderDashboard(w, "streak", newDashboardPage(req, "Streak", "streak", struct {
+		Streak
+		WeekPractice *PracticeTime
+	}{calculateStreak(days, now), practice}))
+}
+
+func dashboardLeaderboardsHandler(w http.ResponseWriter, r *http.Request, req *dashboardRequest) {
+	ratingCategory := req.Filter.Category
+	if ratingCategory == "" {
+		ratingCategory = "Ranked"
+	}
+
+	var activity []*ActivityEntry
+	var ratings []*PlayerRating
+	if req.GuildID != "" {
+		var err error
+		activity, err = GetGuildActivityLeaderboard(r.Context(), req.GuildID, req.Filter, dashboardLeaderboardSize)
+		if err != nil {
+			requestLogger(r).Error("Failed to get activity leaderboard", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		ratings, err = GetRatingLeaderboard(r.Context(), req.GuildID, ratingCategory, dashboardLeaderboardSize)
+		if err != nil {
+			requestLogger(r).Error("Failed to get rating leaderboard", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	renderDashboard(w, "leaderboards", newDashboardPage(req, "Leaderboards", "leaderboards", map[string]any{
+		"NoGuild":        req.GuildID == "",
+		"Activity":       activity,
+		"RatingCategory": ratingCategory,
+		"Ratings":        ratings,
+	}))
+}
+
+// writeChart writes a rendered PNG chart
+func writeChart(w http.ResponseWriter, chart []byte, err error) {
+	if err != nil {
+		slog.Error("Failed to render chart", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	w.Write(chart)
+}
+
+func dashboardWinRateChartHandler(w http.ResponseWriter, r *http.Request, req *dashboardRequest) {
+	days, err := GetUserDailyResults(r.Context(), req.User.ID, req.Filter, userLocation(req.User).String())
+	if err != nil {
+		requestLogger(r).Error("Failed to get daily results", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	chart, err := renderWinRateChart("Win rate over time", cumulativeWinRate(days))
+	writeChart(w, chart, err)
+}
+
+func dashboardMatchupsChartHandler(w http.ResponseWriter, r *http.Request, req *dashboardRequest) {
+	matchups, err := GetUserMatchups(r.Context(), req.User.ID, req.Filter)
+	if err != nil {
+		requestLogger(r).Error("Failed to get user matchups", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	leaders, opponents, records := matchupGrid(matchups)
+	chart, err := renderMatchupHeatmap("Matchups", leaders, opponents, records)
+	writeChart(w, chart, err)
+}
+
+func dashboardCalendarChartHandler(w http.ResponseWriter, r *http.Request, req *dashboardRequest) {
+	loc := userLocation(req.User)
+	days, err := GetUserDailyResults(r.Context(), req.User.ID, req.Filter, loc.String())
+	if err != nil {
+		requestLogger(r).Error("Failed to get daily results", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	gamesPerDay := map[string]int{}
+	for _, day := range days {
+		gamesPerDay[day.Day.Format("2006-01-02")] = day.Games
+	}
+
+	end := time.Now().In(loc)
+	if !req.Filter.To.IsZero() && req.Filter.To.Before(end) {
+		end = req.Filter.To.AddDate(0, 0, -1)
+	}
+	chart, err := renderCalendarHeatmap("Games per day", gamesPerDay, end, streakCalendarWeeks)
+	writeChart(w, chart, err)
+}
+
+// dashboardCommand replies privately, since the reply contains a login link
+var dashboardCommand = &slashCommand[noOptions]{
+	Name:             "dashboard",
+	Description:      "Get a one-time login link for your web dashboard",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Ephemeral:        true,
+	Run:              runDashboard,
+}
+
+func runDashboard(ctx context.Context, c *commandContext, options *noOptions) error {
+	baseURL := dashboardBaseURL()
+	if baseURL == "" {
+		return userError("❌ The dashboard isn't set up on this bot yet (DASHBOARD_BASE_URL is missing).")
+	}
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed("❌ Failed to create a dashboard link. Please try again later.", err)
+	}
+
+	token, err := CreateDashboardLoginToken(ctx, user.ID, c.GuildID(), dashboardLoginTTL)
+	if err != nil {
+		return commandFailed("❌ Failed to create a dashboard link. Please try again later.", fmt.Errorf("failed to create dashboard login token: %w", err))
+	}
+
+	c.Replyf("🖥️ **Your dashboard login link**\n%s/dashboard/login?token=%s\nIt works once and expires in %d minutes. Don't share it!",
+		baseURL, token, int(dashboardLoginTTL.Minutes()))
+	return nil
+}
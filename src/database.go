@@ -1,61 +1,74 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"strconv"
+	"log/slog"
+	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 var DB *sql.DB
 
-// InitDB initializes the database connection with retry logic
-func InitDB() error {
-	dbHost := getEnv("POSTGRES_HOST")
-	dbPort := getEnv("POSTGRES_PORT")
-	dbUser := getEnv("POSTGRES_USER")
-	dbPassword := getEnv("POSTGRES_PASSWORD")
-	dbName := getEnv("POSTGRES_DB")
-
-	// Default values if not set
-	if dbHost == "" {
-		dbHost = "localhost"
+// databaseDSN returns DATABASE_URL if set, otherwise a key/value DSN built from the
+// POSTGRES_* settings
+func databaseDSN() string {
+	if AppConfig.DatabaseURL != "" {
+		return AppConfig.DatabaseURL
 	}
-	if dbPort == "" {
-		dbPort = "5432"
-	}
-
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
 
-	// Get retry configuration from environment variables with defaults
-	maxRetries := 5
-	retryDelaySeconds := 10
-
-	if retriesStr := getEnv("DB_MAX_RETRIES"); retriesStr != "" {
-		if retries, err := strconv.Atoi(retriesStr); err == nil && retries > 0 {
-			maxRetries = retries
-		}
+	params := [][2]string{
+		{"host", AppConfig.PostgresHost},
+		{"port", AppConfig.PostgresPort},
+		{"user", AppConfig.PostgresUser},
+		{"password", AppConfig.PostgresPassword},
+		{"dbname", AppConfig.PostgresDB},
+		{"sslmode", AppConfig.PostgresSSLMode},
+		{"sslrootcert", AppConfig.PostgresSSLRootCert},
 	}
 
-	if delayStr := getEnv("DB_RETRY_DELAY_SECONDS"); delayStr != "" {
-		if delay, err := strconv.Atoi(delayStr); err == nil && delay > 0 {
-			retryDelaySeconds = delay
+	var parts []string
+	for _, param := range params {
+		if param[1] == "" {
+			continue
 		}
+		// Quote every value so passwords with spaces or quotes survive
+		value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(param[1])
+		parts = append(parts, fmt.Sprintf("%s='%s'", param[0], value))
 	}
+	return strings.Join(parts, " ")
+}
 
-	retryDelay := time.Duration(retryDelaySeconds) * time.Second
+// configurePool applies the pool limits from the configuration
+func configurePool(db *sql.DB) {
+	db.SetMaxOpenConns(AppConfig.DBMaxOpenConns)
+	db.SetMaxIdleConns(AppConfig.DBMaxIdleConns)
+	db.SetConnMaxLifetime(AppConfig.DBConnMaxLifetime())
+	db.SetConnMaxIdleTime(AppConfig.DBConnMaxIdleTime())
+}
+
+// InitDB initializes the database connection with retry logic
+func InitDB() error {
+	psqlInfo := databaseDSN()
+
+	maxRetries := AppConfig.DBMaxRetries
+	retryDelay := AppConfig.DBRetryDelay()
 
 	var err error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		log.Printf("Attempting to connect to database (attempt %d/%d)...", attempt, maxRetries)
+		slog.Info("Attempting to connect to database", "attempt", attempt, "max_attempts", maxRetries)
 
-		DB, err = sql.Open("postgres", psqlInfo)
+		var connector *pq.Connector
+		connector, err = pq.NewConnector(psqlInfo)
+		if err == nil {
+			DB = sql.OpenDB(&instrumentedConnector{Connector: connector})
+			configurePool(DB)
+		}
 		if err != nil {
-			log.Printf("Failed to open database connection: %v", err)
+			slog.Warn("Failed to open database connection", "error", err)
 			if attempt == maxRetries {
 				return fmt.Errorf("failed to open database after %d attempts: %w", maxRetries, err)
 			}
@@ -65,18 +78,18 @@ func InitDB() error {
 
 		err = DB.Ping()
 		if err != nil {
-			log.Printf("Failed to ping database: %v", err)
+			slog.Warn("Failed to ping database", "error", err)
 			DB.Close() // Close the connection before retrying
 			if attempt == maxRetries {
 				return fmt.Errorf("failed to ping database after %d attempts: %w", maxRetries, err)
 			}
-			log.Printf("Waiting %v before next attempt...", retryDelay)
+			slog.Info("Waiting before next attempt", "delay", retryDelay)
 			time.Sleep(retryDelay)
 			continue
 		}
 
 		// Success!
-		log.Println("Successfully connected to PostgreSQL database")
+		slog.Info("Successfully connected to PostgreSQL database")
 		return nil
 	}
 
@@ -103,7 +116,60 @@ func CreateTables() error {
 		return fmt.Errorf("failed to create game_results table: %w", err)
 	}
 
-	log.Println("Successfully created all database tables")
+	err = createPlayerGamesView()
+	if err != nil {
+		return fmt.Errorf("failed to create player_games view: %w", err)
+	}
+
+	rebuildRatings, err := createRatingsTable()
+	if err != nil {
+		return fmt.Errorf("failed to create ratings table: %w", err)
+	}
+
+	err = createAPITokensTable()
+	if err != nil {
+		return fmt.Errorf("failed to create api_tokens table: %w", err)
+	}
+
+	err = createDashboardTables()
+	if err != nil {
+		return fmt.Errorf("failed to create dashboard tables: %w", err)
+	}
+
+	err = createGuildCategoriesTable()
+	if err != nil {
+		return fmt.Errorf("failed to create guild_categories table: %w", err)
+	}
+
+	err = createAuditLogTable()
+	if err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+
+	err = createGameGroupsTable()
+	if err != nil {
+		return fmt.Errorf("failed to create game_groups table: %w", err)
+	}
+
+	err = createLFGTables()
+	if err != nil {
+		return fmt.Errorf("failed to create lfg tables: %w", err)
+	}
+
+	err = createPracticeSessionsTable()
+	if err != nil {
+		return fmt.Errorf("failed to create practice_sessions table: %w", err)
+	}
+
+	// Rebuilding writes audit entries, so it waits until every table exists
+	if rebuildRatings {
+		err = rebuildAllRatings()
+		if err != nil {
+			return fmt.Errorf("failed to rebuild ratings: %w", err)
+		}
+	}
+
+	slog.Info("Successfully created all database tables")
 	return nil
 }
 
@@ -128,7 +194,7 @@ func createUsersTable() error {
 		return fmt.Errorf("failed to create users table: %w", err)
 	}
 
-	log.Println("Users table created successfully")
+	slog.Info("Users table created successfully")
 	return nil
 }
 
@@ -142,7 +208,7 @@ func createGameResultsTable() error {
 		opponent VARCHAR(100) NOT NULL,
 		category VARCHAR(50) DEFAULT 'Casual',
 		went_first BOOLEAN NOT NULL,
-		won BOOLEAN NOT NULL,
+		outcome VARCHAR(20) NOT NULL,
 		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 	);
 
@@ -163,7 +229,25 @@ func createGameResultsTable() error {
 		return fmt.Errorf("failed to add category column: %w", err)
 	}
 
-	log.Println("Game results table created successfully")
+	// Add opponent player columns if they don't exist (for existing databases)
+	err = addOpponentPlayerColumnsIfNotExist()
+	if err != nil {
+		return fmt.Errorf("failed to add opponent player columns: %w", err)
+	}
+
+	// Replace the won column with outcome (for existing databases)
+	err = migrateWonToOutcome()
+	if err != nil {
+		return fmt.Errorf("failed to migrate won column: %w", err)
+	}
+
+	// Add the optional game detail columns if they don't exist (for existing databases)
+	err = addGameDetailColumnsIfNotExist()
+	if err != nil {
+		return fmt.Errorf("failed to add game detail columns: %w", err)
+	}
+
+	slog.Info("Game results table created successfully")
 	return nil
 }
 
@@ -198,10 +282,459 @@ func addCategoryColumnIfNotExists() error {
 			return fmt.Errorf("failed to create category index: %w", err)
 		}
 
-		log.Println("Added category column to existing game_results table")
+		slog.Info("Added category column to existing game_results table")
 	} else if err != nil {
 		return fmt.Errorf("failed to check for category column: %w", err)
 	}
 
 	return nil
 }
+
+// addOpponentPlayerColumnsIfNotExist adds the columns linking a game to a second server
+// member and to the guild it was recorded in
+func addOpponentPlayerColumnsIfNotExist() error {
+	query := `
+	ALTER TABLE game_results
+		ADD COLUMN IF NOT EXISTS opponent_user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+		ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'recorded',
+		ADD COLUMN IF NOT EXISTS guild_id VARCHAR(20);
+
+	CREATE INDEX IF NOT EXISTS idx_game_results_opponent_user_id ON game_results(opponent_user_id);
+	CREATE INDEX IF NOT EXISTS idx_game_results_status ON game_results(status);
+	CREATE INDEX IF NOT EXISTS idx_game_results_guild_id ON game_results(guild_id);
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to add opponent player columns: %w", err)
+	}
+
+	return nil
+}
+
+// addGameDetailColumnsIfNotExist adds the optional per-game detail columns. NULL means
+// the detail wasn't recorded.
+func addGameDetailColumnsIfNotExist() error {
+	query := `
+	ALTER TABLE game_results
+		ADD COLUMN IF NOT EXISTS turns INTEGER,
+		ADD COLUMN IF NOT EXISTS life_remaining INTEGER,
+		ADD COLUMN IF NOT EXISTS opponent_life_remaining INTEGER,
+		ADD COLUMN IF NOT EXISTS duration_minutes INTEGER,
+		ADD COLUMN IF NOT EXISTS mulliganed BOOLEAN,
+		ADD COLUMN IF NOT EXISTS key_cards INTEGER;
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to add game detail columns: %w", err)
+	}
+
+	return nil
+}
+
+// migrateWonToOutcome replaces the won column of existing game_results tables with
+// outcome, turning true into a win and false into a loss
+func migrateWonToOutcome() error {
+	checkQuery := `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_name = 'game_results' AND column_name = 'won'
+	`
+
+	var columnName string
+	err := DB.QueryRow(checkQuery).Scan(&columnName)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to check for won column: %w", err)
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin outcome migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The view reads won, so it has to go before the column can. It is recreated
+	// right after the tables are.
+	query := `
+	DROP VIEW IF EXISTS player_games;
+
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS outcome VARCHAR(20);
+
+	UPDATE game_results
+	SET outcome = CASE WHEN won THEN '` + string(OutcomeWin) + `' ELSE '` + string(OutcomeLoss) + `' END
+	WHERE outcome IS NULL;
+
+	ALTER TABLE game_results
+		ALTER COLUMN outcome SET NOT NULL,
+		DROP COLUMN won;
+	`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to migrate won column: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit outcome migration: %w", err)
+	}
+
+	slog.Info("Migrated game_results from won to outcome")
+	return nil
+}
+
+// createPlayerGamesView creates the player_games view, which shows every game from the
+// perspective of each participant. Games against another server member are stored once
+// in game_results; once confirmed, the view adds a mirrored row for the opponent player.
+// Mirrored outcomes come from Outcome.Mirrored. Life totals are swapped too.
+func createPlayerGamesView() error {
+	query := `
+	DROP VIEW IF EXISTS player_games;
+
+	CREATE VIEW player_games AS
+		SELECT id AS game_id, user_id, opponent_user_id, guild_id, leader, opponent, category,
+			went_first, outcome, status, created_at,
+			turns, life_remaining, opponent_life_remaining, duration_minutes,
+			mulliganed, key_cards, session_id
+		FROM game_results
+		UNION ALL
+		SELECT id AS game_id, opponent_user_id AS user_id, user_id AS opponent_user_id, guild_id,
+			opponent AS leader, leader AS opponent, category,
+			NOT went_first AS went_first,
+			` + mirroredOutcomeSQL() + ` AS outcome,
+			status, created_at,
+			turns, opponent_life_remaining AS life_remaining, life_remaining AS opponent_life_remaining,
+			duration_minutes,
+			-- the mulligan was the reporter's, so the opponent's is unknown
+			NULL::BOOLEAN AS mulliganed, NULL::INTEGER AS key_cards,
+			-- and so was the practice session the game was recorded in
+			NULL::INTEGER AS session_id
+		FROM game_results
+		WHERE opponent_user_id IS NOT NULL AND status = 'confirmed';
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create player_games view: %w", err)
+	}
+
+	slog.Info("Player games view created successfully")
+	return nil
+}
+
+// createRatingsTable creates the ratings table, holding one Glicko-2 rating per server,
+// user and category. It reports whether ratings from before they were kept per server
+// were dropped, in which case they need rebuilding once every table exists.
+func createRatingsTable() (bool, error) {
+	rebuild, err := dropGlobalRatingsTable()
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+	CREATE TABLE IF NOT EXISTS ratings (
+		guild_id VARCHAR(20) NOT NULL,
+		user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+		category VARCHAR(50) NOT NULL,
+		rating DOUBLE PRECISION NOT NULL,
+		rd DOUBLE PRECISION NOT NULL,
+		volatility DOUBLE PRECISION NOT NULL,
+		games INTEGER NOT NULL DEFAULT 0,
+		wins INTEGER NOT NULL DEFAULT 0,
+		losses INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		PRIMARY KEY (guild_id, user_id, category)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_ratings_guild_category_rating ON ratings(guild_id, category, rating DESC);
+	CREATE INDEX IF NOT EXISTS idx_ratings_user_id ON ratings(user_id);
+	`
+
+	_, err = DB.Exec(query)
+	if err != nil {
+		return false, fmt.Errorf("failed to create ratings table: %w", err)
+	}
+
+	slog.Info("Ratings table created successfully")
+	return rebuild, nil
+}
+
+// dropGlobalRatingsTable drops a ratings table from before ratings were kept per
+// server. Ratings are derived from confirmed games, so nothing is lost: they are
+// rebuilt per server by rebuildAllRatings.
+func dropGlobalRatingsTable() (bool, error) {
+	checkQuery := `
+		SELECT
+			EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'ratings'),
+			EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'ratings' AND column_name = 'guild_id')
+	`
+
+	var tableExists, hasGuild bool
+	err := DB.QueryRow(checkQuery).Scan(&tableExists, &hasGuild)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ratings table: %w", err)
+	}
+	if !tableExists || hasGuild {
+		return false, nil
+	}
+
+	_, err = DB.Exec(`DROP TABLE ratings`)
+	if err != nil {
+		return false, fmt.Errorf("failed to drop global ratings table: %w", err)
+	}
+
+	slog.Info("Dropped global ratings table to rebuild it per server")
+	return true, nil
+}
+
+// rebuildAllRatings recomputes the ratings of every server with confirmed player games
+func rebuildAllRatings() error {
+	rows, err := DB.Query(`
+		SELECT DISTINCT guild_id FROM game_results
+		WHERE opponent_user_id IS NOT NULL AND status = $1 AND guild_id IS NOT NULL
+	`, GameStatusConfirmed)
+	if err != nil {
+		return fmt.Errorf("failed to get rated guilds: %w", err)
+	}
+
+	var guildIDs []string
+	for rows.Next() {
+		var guildID string
+		err = rows.Scan(&guildID)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan rated guild: %w", err)
+		}
+		guildIDs = append(guildIDs, guildID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to get rated guilds: %w", err)
+	}
+
+	for _, guildID := range guildIDs {
+		gamesReplayed, err := RecomputeRatings(withSystemAuditSource(context.Background(), guildID), guildID)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild ratings for guild %s: %w", guildID, err)
+		}
+		slog.Info("Rebuilt guild ratings", "guild_id", guildID, "games_replayed", gamesReplayed)
+	}
+
+	return nil
+}
+
+// createAPITokensTable creates the api_tokens table. Only a SHA-256 hash of each
+// token is stored, and each user has at most one token.
+func createAPITokensTable() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS api_tokens (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		token_hash CHAR(64) UNIQUE NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		last_used_at TIMESTAMP WITH TIME ZONE
+	);
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create api_tokens table: %w", err)
+	}
+
+	slog.Info("API tokens table created successfully")
+	return nil
+}
+
+// createDashboardTables creates the tables behind web dashboard logins: one-time login
+// links issued by /dashboard, and the browser sessions they are exchanged for
+func createDashboardTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS dashboard_login_tokens (
+		token_hash CHAR(64) PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		guild_id VARCHAR(20),
+		expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		used_at TIMESTAMP WITH TIME ZONE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS dashboard_sessions (
+		token_hash CHAR(64) PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		guild_id VARCHAR(20),
+		expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_dashboard_login_tokens_user_id ON dashboard_login_tokens(user_id);
+	CREATE INDEX IF NOT EXISTS idx_dashboard_sessions_user_id ON dashboard_sessions(user_id);
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create dashboard tables: %w", err)
+	}
+
+	slog.Info("Dashboard tables created successfully")
+	return nil
+}
+
+// createGuildCategoriesTable creates the guild_categories table. A server with no rows
+// uses the default categories; its first change copies the defaults in, so from then
+// on the rows are the server's full list.
+func createGuildCategoriesTable() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS guild_categories (
+		id SERIAL PRIMARY KEY,
+		guild_id VARCHAR(20) NOT NULL,
+		name VARCHAR(50) NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_guild_categories_guild_name ON guild_categories(guild_id, LOWER(name));
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create guild_categories table: %w", err)
+	}
+
+	slog.Info("Guild categories table created successfully")
+	return nil
+}
+
+// createAuditLogTable creates the audit_log table, recording changes to the bot's data.
+// Entries outlive the users involved, so the actor is set to NULL when they're deleted.
+func createAuditLogTable() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id SERIAL PRIMARY KEY,
+		action VARCHAR(50) NOT NULL,
+		actor_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		guild_id VARCHAR(20),
+		target_type VARCHAR(50),
+		target_id INTEGER,
+		before JSONB,
+		after JSONB,
+		interaction_id VARCHAR(20),
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_actor_user_id ON audit_log(actor_user_id);
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+
+	slog.Info("Audit log table created successfully")
+	return nil
+}
+
+// createGameGroupsTable creates the game_groups table, holding the role and private
+// channel behind each /create-game group. Deleted groups are removed; archived ones
+// keep their read-only channel.
+func createGameGroupsTable() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS game_groups (
+		id SERIAL PRIMARY KEY,
+		guild_id VARCHAR(20) NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		role_id VARCHAR(20) NOT NULL,
+		channel_id VARCHAR(20) UNIQUE NOT NULL,
+		creator_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'active',
+		last_activity_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		archived_at TIMESTAMP WITH TIME ZONE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_game_groups_guild_name ON game_groups(guild_id, LOWER(name));
+	CREATE INDEX IF NOT EXISTS idx_game_groups_status ON game_groups(status);
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create game_groups table: %w", err)
+	}
+
+	slog.Info("Game groups table created successfully")
+	return nil
+}
+
+// createLFGTables creates the /lfg tables: the matches the bot made, each with its
+// temporary channel and role, and the queue entries waiting for or paired into one
+func createLFGTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS lfg_matches (
+		id SERIAL PRIMARY KEY,
+		guild_id VARCHAR(20) NOT NULL,
+		category VARCHAR(50) NOT NULL,
+		channel_id VARCHAR(20),
+		role_id VARCHAR(20),
+		game_result_id INTEGER REFERENCES game_results(id) ON DELETE SET NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		closed_at TIMESTAMP WITH TIME ZONE
+	);
+
+	CREATE TABLE IF NOT EXISTS lfg_queue (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		guild_id VARCHAR(20) NOT NULL,
+		leader VARCHAR(100) NOT NULL,
+		wanted_opponents TEXT[] NOT NULL DEFAULT '{}',
+		category VARCHAR(50) NOT NULL,
+		available_until TIMESTAMP WITH TIME ZONE NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+		match_id INTEGER REFERENCES lfg_matches(id) ON DELETE SET NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_lfg_queue_waiting ON lfg_queue(user_id, guild_id) WHERE status = 'waiting';
+	CREATE INDEX IF NOT EXISTS idx_lfg_queue_guild_status ON lfg_queue(guild_id, status);
+	CREATE INDEX IF NOT EXISTS idx_lfg_queue_match_id ON lfg_queue(match_id);
+	CREATE INDEX IF NOT EXISTS idx_lfg_matches_open ON lfg_matches(created_at) WHERE closed_at IS NULL;
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create lfg tables: %w", err)
+	}
+
+	slog.Info("LFG tables created successfully")
+	return nil
+}
+
+// createPracticeSessionsTable creates the practice_sessions table and links game_results
+// to the session they were recorded in. A user has at most one open session, one
+// with no ended_at.
+func createPracticeSessionsTable() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS practice_sessions (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		guild_id VARCHAR(20),
+		started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		ended_at TIMESTAMP WITH TIME ZONE
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_practice_sessions_open ON practice_sessions(user_id) WHERE ended_at IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_practice_sessions_user_started ON practice_sessions(user_id, started_at);
+
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS session_id INTEGER REFERENCES practice_sessions(id) ON DELETE SET NULL;
+	CREATE INDEX IF NOT EXISTS idx_game_results_session_id ON game_results(session_id);
+	`
+
+	_, err := DB.Exec(query)
+	if err != nil {
+		return fmt.Errorf("failed to create practice_sessions table: %w", err)
+	}
+
+	slog.Info("Practice sessions table created successfully")
+	return nil
+}
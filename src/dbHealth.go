@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// dbHealthCheckTimeout bounds each background database ping
+const dbHealthCheckTimeout = 5 * time.Second
+
+// dbHealthy is true while the last background ping succeeded
+var dbHealthy atomic.Bool
+
+// monitorDatabase pings the database every DB_HEALTH_CHECK_INTERVAL_SECONDS until ctx
+// is cancelled. database/sql replaces broken connections on its own; the monitor
+// makes outages visible by logging when the connection is lost and restored and
+// counting both in bot_db_connection_events_total.
+func monitorDatabase(ctx context.Context) {
+	dbHealthy.Store(true)
+
+	ticker := time.NewTicker(AppConfig.DBHealthCheckInterval())
+	defer ticker.Stop()
+
+	var lostAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, dbHealthCheckTimeout)
+		err := DB.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch {
+		case err != nil && dbHealthy.Load():
+			lostAt = time.Now()
+			dbHealthy.Store(false)
+			dbConnectionEventsTotal.Inc("lost")
+			slog.Error("Database connection lost", "error", err)
+		case err != nil:
+			slog.Warn("Database still unreachable", "down_for", time.Since(lostAt).Round(time.Second), "error", err)
+		case !dbHealthy.Load():
+			dbHealthy.Store(true)
+			dbConnectionEventsTotal.Inc("restored")
+			slog.Info("Database connection restored", "down_for", time.Since(lostAt).Round(time.Second))
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"flag"
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,200 +11,119 @@ import (
 )
 
 var (
-	// commandPrefix = "!"
-	GuildID = flag.String("guild", "", "Test guild ID. If not passed - bot registers commands globally")
+	// adminPermission restricts a command to server administrators by default
+	adminPermission int64 = discordgo.PermissionAdministrator
+
+	// anywhereContexts allows a command in servers, in DMs with the bot and, when the
+	// app is installed to a user's account, in group DMs and DMs with other users
+	anywhereContexts = &[]discordgo.InteractionContextType{
+		discordgo.InteractionContextGuild,
+		discordgo.InteractionContextBotDM,
+		discordgo.InteractionContextPrivateChannel,
+	}
+
+	// guildOnlyContexts limits a command to servers, for commands that act on the server
+	guildOnlyContexts = &[]discordgo.InteractionContextType{
+		discordgo.InteractionContextGuild,
+	}
+
+	// personalInstallTypes lets a command be used from a server install or a user install
+	personalInstallTypes = &[]discordgo.ApplicationIntegrationType{
+		discordgo.ApplicationIntegrationGuildInstall,
+		discordgo.ApplicationIntegrationUserInstall,
+	}
+
+	// guildInstallTypes limits a command to servers that installed the bot
+	guildInstallTypes = &[]discordgo.ApplicationIntegrationType{
+		discordgo.ApplicationIntegrationGuildInstall,
+	}
 )
 
 var (
-	commands = []*discordgo.ApplicationCommand{
-		{
-			Name:        "helloworld",
-			Description: "Sends a hello world message",
-		},
-		{
-			Name:        "ping",
-			Description: "Ping the bot to check if it's online",
-		},
-		{
-			Name:        "create-game",
-			Description: "Create special channel for a game",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "game",
-					Description: "The game to create a channel for",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "set-timezone",
-			Description: "Set your timezone for accurate time tracking",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "timezone",
-					Description: "Your timezone (e.g., America/New_York, Europe/London, Asia/Tokyo)",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "record-game",
-			Description: "Record the result of a single game",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "leader",
-					Description: "Your leader/character",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "opponent",
-					Description: "Your opponent's leader/character",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "category",
-					Description: "Game category (Casual, Ranked, Locals, Tournament, etc.)",
-					Required:    false,
-					Choices: []*discordgo.ApplicationCommandOptionChoice{
-						{Name: "Casual", Value: "Casual"},
-						{Name: "Ranked", Value: "Ranked"},
-						{Name: "Locals", Value: "Locals"},
-						{Name: "Regional", Value: "Regional"},
-						{Name: "National", Value: "National"},
-						{Name: "Tournament", Value: "Tournament"},
-						{Name: "Practice", Value: "Practice"},
-						{Name: "Online", Value: "Online"},
-					},
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionBoolean,
-					Name:        "went_first",
-					Description: "Did you go first?",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionBoolean,
-					Name:        "won",
-					Description: "Did you win?",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "record-games",
-			Description: "Record multiple games with the same leader",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "leader",
-					Description: "Your leader/character for all games",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "category",
-					Description: "Game category for all games (Casual, Ranked, Locals, Tournament, etc.)",
-					Required:    false,
-					Choices: []*discordgo.ApplicationCommandOptionChoice{
-						{Name: "Casual", Value: "Casual"},
-						{Name: "Ranked", Value: "Ranked"},
-						{Name: "Locals", Value: "Locals"},
-						{Name: "Regional", Value: "Regional"},
-						{Name: "National", Value: "National"},
-						{Name: "Tournament", Value: "Tournament"},
-						{Name: "Practice", Value: "Practice"},
-						{Name: "Online", Value: "Online"},
-					},
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "games",
-					Description: "Games data: opponent1,first/second,win/loss;opponent2,first/second,win/loss",
-					Required:    true,
-				},
-			},
-		},
+	// slashCommands are all the bot's slash commands, in registration order
+	slashCommands = []commandSpec{
+		helloWorldCommand,
+		pingCommand,
+		createGameCommand,
+		gameCommand,
+		lfgCommand,
+		setTimezoneCommand,
+		recordGameCommand,
+		recordGamesCommand,
+		headToHeadCommand,
+		ratingCommand,
+		ratingLeaderboardCommand,
+		metaCommand,
+		statsCommand,
+		matchupsCommand,
+		streakCommand,
+		tempoCommand,
+		mulliganStatsCommand,
+		sessionCommand,
+		apiTokenCommand,
+		dashboardCommand,
+		myDataCommand,
+		forgetMeCommand,
+		categoryCommand,
+		auditCommand,
+		ratingRecomputeCommand,
 	}
+
+	// commands are the definitions registered with Discord
+	commands = commandDefinitions(slashCommands)
 )
 
+// interactionHandler handles one routed interaction. ctx carries the interaction's
+// deadline and should be passed to every database call the handler makes.
+type interactionHandler func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate)
+
 func discordAddHandlers(discord *discordgo.Session) {
 	// discord.AddHandler(discordPrefixedCommands)
 
-	commandHandlers := map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
-		"helloworld":   basicCommand,
-		"create-game":  createGameCommand,
-		"ping":         basicCommand,
-		"set-timezone": setTimezoneCommand,
-		"record-game":  recordGameCommand,
-		"record-games": recordGamesCommand,
+	// Component handlers are keyed by the first segment of the button's custom ID
+	componentHandlers := map[string]interactionHandler{
+		playerGameComponentPrefix: playerGameComponent,
+		forgetMeComponentPrefix:   forgetMeComponent,
+		gameGroupComponentPrefix:  gameGroupComponent,
+		lfgComponentPrefix:        lfgComponent,
 	}
 
-	discord.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
-			h(s, i)
-		}
-	})
-}
-
-func basicCommand(discord *discordgo.Session, i *discordgo.InteractionCreate) {
-	fmt.Println("Basic Command executed")
-
-	// Defer the response
-	err := discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
-	if err != nil {
-		fmt.Println("Failed to defer interaction response:", err)
-		return
+	// Modal handlers are keyed by the first segment of the modal's custom ID
+	modalHandlers := map[string]interactionHandler{
+		lfgResultModalPrefix: lfgResultModal,
 	}
 
-	_, err = discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-		Content: "Hey there! Congratulations, you just executed your first slash command",
-	})
-	if err != nil {
-		fmt.Println("Failed to send followup message:", err)
-		return
+	router := &interactionRouter{
+		commands:     commandHandlers(slashCommands),
+		autocomplete: commandAutocompleters(slashCommands),
+		components:   componentHandlers,
+		modals:       modalHandlers,
 	}
+	discord.AddHandler(router.handle)
 }
 
-func createGameCommand(discord *discordgo.Session, i *discordgo.InteractionCreate) {
-	fmt.Println("Command executed")
-
-	// Defer the response
-	err := discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
-	if err != nil {
-		fmt.Println("Failed to defer interaction response:", err)
-		return
-	}
-
-	gameName := i.ApplicationCommandData().Options[0].StringValue()
-
-	role, _ := createDiscordRole(gameName, discord, i.GuildID)
+// noOptions is the options struct for commands that take none
+type noOptions struct{}
 
-	channelID, err := createDiscordTextChannel(gameName, discord, i.GuildID, role.ID)
-	if err != nil {
-		fmt.Println("Failed to create channel:", err)
-		return
-	}
+var helloWorldCommand = &slashCommand[noOptions]{
+	Name:             "helloworld",
+	Description:      "Sends a hello world message",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              basicCommand,
+}
 
-	fmt.Print("Channel created: ", channelID)
-	// setDiscordPermissions(discord, channelID, "TheReds", DISCORD_ALLOW)
-	// setDiscordPermissions(discord, channelID, DISCORD_ROLE, DISCORD_DENY)
+var pingCommand = &slashCommand[noOptions]{
+	Name:             "ping",
+	Description:      "Ping the bot to check if it's online",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              basicCommand,
+}
 
-	_, err = discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-		Content: "Creating a new game channel...",
-	})
-	if err != nil {
-		fmt.Println("Failed to send followup message:", err)
-		return
-	}
+func basicCommand(ctx context.Context, c *commandContext, options *noOptions) error {
+	c.Reply("Hey there! Congratulations, you just executed your first slash command")
+	return nil
 }
 
 // isValidTimezone checks if the given timezone string is valid
@@ -213,220 +132,178 @@ func isValidTimezone(tz string) bool {
 	return err == nil
 }
 
-func setTimezoneCommand(discord *discordgo.Session, i *discordgo.InteractionCreate) {
-	fmt.Println("Set timezone command executed")
-
-	// Defer the response
-	err := discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
-	if err != nil {
-		fmt.Println("Failed to defer interaction response:", err)
-		return
-	}
-
-	// Get the timezone from the command options
-	timezone := i.ApplicationCommandData().Options[0].StringValue()
-	timezone = strings.TrimSpace(timezone)
+type setTimezoneOptions struct {
+	Timezone string `option:"timezone" description:"Your timezone (e.g., America/New_York, Europe/London, Asia/Tokyo)" required:"true"`
+}
 
-	// Validate the timezone
-	if !isValidTimezone(timezone) {
-		_, err = discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-			Content: "❌ Invalid timezone! Please use a valid timezone like:\n• America/New_York\n• Europe/London\n• Asia/Tokyo\n• UTC\n\nFor a full list, see: https://en.wikipedia.org/wiki/List_of_tz_database_time_zones",
-		})
-		if err != nil {
-			fmt.Println("Failed to send error followup message:", err)
-		}
-		return
+func (o *setTimezoneOptions) validate() error {
+	if !isValidTimezone(o.Timezone) {
+		return fmt.Errorf("❌ Invalid timezone! Please use a valid timezone like:\n• America/New_York\n• Europe/London\n• Asia/Tokyo\n• UTC\n\nFor a full list, see: https://en.wikipedia.org/wiki/List_of_tz_database_time_zones")
 	}
+	return nil
+}
 
-	// Get the user's Discord ID
-	discordID := i.Member.User.ID
-	username := i.Member.User.Username
-	discriminator := i.Member.User.Discriminator
+var setTimezoneCommand = &slashCommand[setTimezoneOptions]{
+	Name:             "set-timezone",
+	Description:      "Set your timezone for accurate time tracking",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runSetTimezone,
+}
 
+func runSetTimezone(ctx context.Context, c *commandContext, options *setTimezoneOptions) error {
 	// Get or create the user first
-	_, err = GetOrCreateUser(discordID, username, discriminator)
+	user, err := c.User(ctx)
 	if err != nil {
-		fmt.Printf("Failed to get or create user: %v\n", err)
-		_, followupErr := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-			Content: "❌ Failed to set timezone. Please try again later.",
-		})
-		if followupErr != nil {
-			fmt.Println("Failed to send error followup message:", followupErr)
-		}
-		return
+		return commandFailed("❌ Failed to set timezone. Please try again later.", err)
 	}
 
 	// Update the user's timezone
-	err = UpdateUserTimezone(discordID, timezone)
+	err = UpdateUserTimezone(ctx, user.DiscordID, options.Timezone)
 	if err != nil {
-		fmt.Printf("Failed to update user timezone: %v\n", err)
-		_, followupErr := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-			Content: "❌ Failed to set timezone. Please try again later.",
-		})
-		if followupErr != nil {
-			fmt.Println("Failed to send error followup message:", followupErr)
-		}
-		return
+		return commandFailed("❌ Failed to set timezone. Please try again later.", fmt.Errorf("failed to update user timezone: %w", err))
 	}
 
 	// Get current time in the user's timezone for confirmation
-	loc, _ := time.LoadLocation(timezone)
+	loc, _ := time.LoadLocation(options.Timezone)
 	currentTime := time.Now().In(loc)
 
-	_, err = discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-		Content: fmt.Sprintf("✅ Successfully set your timezone to **%s**!\n🕐 Your current time is: %s",
-			timezone, currentTime.Format("Monday, January 2, 2006 at 3:04 PM MST")),
-	})
-	if err != nil {
-		fmt.Println("Failed to send success followup message:", err)
-		return
-	}
+	c.Replyf("✅ Successfully set your timezone to **%s**!\n🕐 Your current time is: %s",
+		options.Timezone, currentTime.Format("Monday, January 2, 2006 at 3:04 PM MST"))
 
-	fmt.Printf("User %s (%s) set timezone to %s\n", username, discordID, timezone)
+	c.Logger().Info("Timezone updated", "username", user.Username, "timezone", options.Timezone)
+	return nil
 }
 
-func recordGameCommand(discord *discordgo.Session, i *discordgo.InteractionCreate) {
-	fmt.Println("Record game command executed")
+// gameDetailsLine shows a game's optional details on their own line, or nothing if
+// none were recorded
+func gameDetailsLine(details GameDetails) string {
+	summary := details.Summary()
+	if summary == "" {
+		return ""
+	}
+	return "\n⏱️ " + summary
+}
 
-	// Defer the response
-	err := discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
-	if err != nil {
-		fmt.Println("Failed to defer interaction response:", err)
-		return
+// privateGameNote tells the user that games logged outside a server are private: they
+// are stored without a server, so they only show up in the user's own stats
+func privateGameNote(c *commandContext) string {
+	if c.GuildID() != "" {
+		return ""
 	}
+	return "\n🔒 Logged privately. Games recorded in DMs only appear in your own stats, not in any server's reports."
+}
 
-	// Extract command options
-	options := i.ApplicationCommandData().Options
-	leader := ""
-	opponent := ""
-	category := "Casual" // Default category
-	wentFirst := false
-	won := false
-
-	for _, option := range options {
-		switch option.Name {
-		case "leader":
-			leader = option.StringValue()
-		case "opponent":
-			opponent = option.StringValue()
-		case "category":
-			category = NormalizeCategory(option.StringValue())
-		case "went_first":
-			wentFirst = option.BoolValue()
-		case "won":
-			won = option.BoolValue()
-		}
+type recordGameOptions struct {
+	Leader         string          `option:"leader" description:"Your leader/character" required:"true"`
+	Opponent       string          `option:"opponent" description:"Your opponent's leader/character" required:"true"`
+	Category       string          `option:"category" description:"Game category (Casual, Ranked, Locals, Tournament, etc.)" autocomplete:"category"`
+	WentFirst      bool            `option:"went_first" description:"Did you go first?" required:"true"`
+	Outcome        Outcome         `option:"outcome" description:"How did the game end?" required:"true" choices:"outcome"`
+	OpponentPlayer *discordgo.User `option:"opponent_player" description:"The server member you played against (they will be asked to confirm)"`
+	Turns          int             `option:"turns" description:"How many turns the game lasted" min:"1" max:"50"`
+	Life           int             `option:"life" description:"Your life cards left at the end" min:"0" max:"10" default:"-1"`
+	OpponentLife   int             `option:"opponent_life" description:"Your opponent's life cards left at the end" min:"0" max:"10" default:"-1"`
+	Duration       int             `option:"duration" description:"How long the game took, in minutes" min:"1" max:"240"`
+	Mulliganed     *bool           `option:"mulliganed" description:"Whether you mulliganed your opening hand"`
+	KeyCards       int             `option:"key_cards" description:"How many of your key cards you opened with" min:"0" max:"5" default:"-1"`
+}
+
+// details returns the optional game details the user filled in
+func (o *recordGameOptions) details() GameDetails {
+	var details GameDetails
+	if o.Turns > 0 {
+		details.Turns = &o.Turns
+	}
+	if o.Life >= 0 {
+		details.LifeRemaining = &o.Life
+	}
+	if o.OpponentLife >= 0 {
+		details.OpponentLifeRemaining = &o.OpponentLife
+	}
+	if o.Duration > 0 {
+		details.DurationMinutes = &o.Duration
 	}
+	details.Mulliganed = o.Mulliganed
+	if o.KeyCards >= 0 {
+		details.KeyCards = &o.KeyCards
+	}
+	return details
+}
 
-	// Get the user's Discord ID
-	discordID := i.Member.User.ID
-	username := i.Member.User.Username
-	discriminator := i.Member.User.Discriminator
+var recordGameCommand = &slashCommand[recordGameOptions]{
+	Name:             "record-game",
+	Description:      "Record the result of a single game",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runRecordGame,
+}
 
+func runRecordGame(ctx context.Context, c *commandContext, options *recordGameOptions) error {
 	// Get or create the user
-	user, err := GetOrCreateUser(discordID, username, discriminator)
+	user, err := c.User(ctx)
 	if err != nil {
-		fmt.Printf("Failed to get or create user: %v\n", err)
-		_, followupErr := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-			Content: "❌ Failed to record game. Please try again later.",
-		})
-		if followupErr != nil {
-			fmt.Println("Failed to send error followup message:", followupErr)
-		}
-		return
+		return commandFailed("❌ Failed to record game. Please try again later.", err)
+	}
+
+	options.Category, err = resolveGuildCategory(ctx, c, options.Category)
+	if err != nil {
+		return err
+	}
+
+	if options.OpponentPlayer != nil {
+		return recordPlayerGame(ctx, c, user, options)
 	}
 
 	// Create the game result
-	_, err = CreateGameResult(user.ID, leader, opponent, category, wentFirst, won)
+	gameResult, err := CreateGameResult(ctx, user.ID, c.GuildID(), options.Leader, options.Opponent, options.Category, options.WentFirst, options.Outcome, options.details())
 	if err != nil {
-		fmt.Printf("Failed to create game result: %v\n", err)
-		_, followupErr := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-			Content: "❌ Failed to record game. Please try again later.",
-		})
-		if followupErr != nil {
-			fmt.Println("Failed to send error followup message:", followupErr)
-		}
-		return
+		return commandFailed("❌ Failed to record game. Please try again later.", fmt.Errorf("failed to create game result: %w", err))
 	}
 
 	// Format response
 	turnText := "second"
-	if wentFirst {
+	if options.WentFirst {
 		turnText = "first"
 	}
-	resultText := "lost"
-	resultEmoji := "❌"
-	if won {
-		resultText = "won"
-		resultEmoji = "✅"
-	}
 
-	_, err = discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-		Content: fmt.Sprintf("%s **Game Recorded!**\n🎮 **%s** vs **%s**\n📂 Category: **%s**\n🎯 Went **%s** • %s **%s**",
-			resultEmoji, leader, opponent, category, turnText, resultEmoji, resultText),
-	})
-	if err != nil {
-		fmt.Println("Failed to send success followup message:", err)
-		return
-	}
+	c.Replyf("%s **Game Recorded!**\n🎮 **%s** vs **%s**\n📂 Category: **%s**\n🎯 Went **%s** • %s **%s**%s%s%s",
+		options.Outcome.Emoji(), options.Leader, options.Opponent, options.Category, turnText, options.Outcome.Emoji(), options.Outcome.Label(),
+		gameDetailsLine(gameResult.GameDetails), sessionGameNote(gameResult), privateGameNote(c))
 
-	fmt.Printf("User %s recorded game: %s vs %s (went %s, %s)\n", username, leader, opponent, turnText, resultText)
+	c.Logger().Info("Game recorded", "username", user.Username, "leader", options.Leader, "opponent", options.Opponent, "went_first", options.WentFirst, "outcome", options.Outcome)
+	return nil
 }
 
-func recordGamesCommand(discord *discordgo.Session, i *discordgo.InteractionCreate) {
-	fmt.Println("Record games command executed")
+type recordGamesOptions struct {
+	Leader   string `option:"leader" description:"Your leader/character for all games" required:"true"`
+	Category string `option:"category" description:"Game category for all games (Casual, Ranked, Locals, Tournament, etc.)" autocomplete:"category"`
+	Games    string `option:"games" description:"Games data: opponent,first/second,result;... Results: win, loss, draw, id, concede, penalty" required:"true"`
+}
 
-	// Defer the response
-	err := discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
-	if err != nil {
-		fmt.Println("Failed to defer interaction response:", err)
-		return
-	}
+var recordGamesCommand = &slashCommand[recordGamesOptions]{
+	Name:             "record-games",
+	Description:      "Record multiple games with the same leader",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runRecordGames,
+}
 
-	// Extract command options
-	options := i.ApplicationCommandData().Options
-	leader := ""
-	category := "Casual" // Default category
-	gamesData := ""
-
-	for _, option := range options {
-		switch option.Name {
-		case "leader":
-			leader = option.StringValue()
-		case "category":
-			category = NormalizeCategory(option.StringValue())
-		case "games":
-			gamesData = option.StringValue()
-		}
+func runRecordGames(ctx context.Context, c *commandContext, options *recordGamesOptions) error {
+	// Get or create the user
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed("❌ Failed to record games. Please try again later.", err)
 	}
 
-	// Get the user's Discord ID
-	discordID := i.Member.User.ID
-	username := i.Member.User.Username
-	discriminator := i.Member.User.Discriminator
-
-	// Get or create the user
-	user, err := GetOrCreateUser(discordID, username, discriminator)
+	options.Category, err = resolveGuildCategory(ctx, c, options.Category)
 	if err != nil {
-		fmt.Printf("Failed to get or create user: %v\n", err)
-		_, followupErr := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-			Content: "❌ Failed to record games. Please try again later.",
-		})
-		if followupErr != nil {
-			fmt.Println("Failed to send error followup message:", followupErr)
-		}
-		return
+		return err
 	}
 
 	// Parse games data
-	// Expected format: opponent1,first/second,win/loss;opponent2,first/second,win/loss
-	games := strings.Split(gamesData, ";")
+	// Expected format: opponent1,first/second,result;opponent2,first/second,result
+	games := strings.Split(options.Games, ";")
 	successCount := 0
 	var gameResults []string
 
@@ -438,18 +315,12 @@ func recordGamesCommand(discord *discordgo.Session, i *discordgo.InteractionCrea
 
 		parts := strings.Split(gameStr, ",")
 		if len(parts) != 3 {
-			_, followupErr := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-				Content: fmt.Sprintf("❌ Invalid game format: '%s'\nExpected format: opponent,first/second,win/loss", gameStr),
-			})
-			if followupErr != nil {
-				fmt.Println("Failed to send error followup message:", followupErr)
-			}
-			return
+			return userError("❌ Invalid game format: '%s'\nExpected format: opponent,first/second,result", gameStr)
 		}
 
 		opponent := strings.TrimSpace(parts[0])
 		turnStr := strings.ToLower(strings.TrimSpace(parts[1]))
-		resultStr := strings.ToLower(strings.TrimSpace(parts[2]))
+		resultStr := strings.TrimSpace(parts[2])
 
 		// Parse turn order
 		var wentFirst bool
@@ -458,42 +329,19 @@ func recordGamesCommand(discord *discordgo.Session, i *discordgo.InteractionCrea
 		} else if turnStr == "second" {
 			wentFirst = false
 		} else {
-			_, followupErr := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-				Content: fmt.Sprintf("❌ Invalid turn format: '%s'\nUse 'first' or 'second'", turnStr),
-			})
-			if followupErr != nil {
-				fmt.Println("Failed to send error followup message:", followupErr)
-			}
-			return
+			return userError("❌ Invalid turn format: '%s'\nUse 'first' or 'second'", turnStr)
 		}
 
 		// Parse result
-		var won bool
-		if resultStr == "win" || resultStr == "won" {
-			won = true
-		} else if resultStr == "loss" || resultStr == "lost" || resultStr == "lose" {
-			won = false
-		} else {
-			_, followupErr := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-				Content: fmt.Sprintf("❌ Invalid result format: '%s'\nUse 'win/won' or 'loss/lost/lose'", resultStr),
-			})
-			if followupErr != nil {
-				fmt.Println("Failed to send error followup message:", followupErr)
-			}
-			return
+		outcome, ok := ParseOutcome(resultStr)
+		if !ok {
+			return userError("❌ Invalid result format: '%s'\nUse 'win', 'loss', 'draw', 'id' (intentional draw), 'concede' or 'penalty'", resultStr)
 		}
 
 		// Create the game result
-		_, err = CreateGameResult(user.ID, leader, opponent, category, wentFirst, won)
+		_, err = CreateGameResult(ctx, user.ID, c.GuildID(), options.Leader, opponent, options.Category, wentFirst, outcome, GameDetails{})
 		if err != nil {
-			fmt.Printf("Failed to create game result: %v\n", err)
-			_, followupErr := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-				Content: fmt.Sprintf("❌ Failed to record game against %s. Please try again later.", opponent),
-			})
-			if followupErr != nil {
-				fmt.Println("Failed to send error followup message:", followupErr)
-			}
-			return
+			return commandFailed(fmt.Sprintf("❌ Failed to record game against %s. Please try again later.", opponent), fmt.Errorf("failed to create game result: %w", err))
 		}
 
 		successCount++
@@ -503,51 +351,15 @@ func recordGamesCommand(discord *discordgo.Session, i *discordgo.InteractionCrea
 		if wentFirst {
 			turnText = "first"
 		}
-		resultText := "lost"
-		resultEmoji := "❌"
-		if won {
-			resultText = "won"
-			resultEmoji = "✅"
-		}
 
 		gameResults = append(gameResults, fmt.Sprintf("%s **%s** vs **%s** (went %s, %s)",
-			resultEmoji, leader, opponent, turnText, resultText))
+			outcome.Emoji(), options.Leader, opponent, turnText, strings.ToLower(outcome.Label())))
 	}
 
 	// Send success message
-	responseContent := fmt.Sprintf("✅ **%s Games Recorded!**\n📂 Category: **%s**\n\n%s",
-		strconv.Itoa(successCount), category, strings.Join(gameResults, "\n"))
+	c.Replyf("✅ **%s Games Recorded!**\n📂 Category: **%s**\n\n%s%s",
+		strconv.Itoa(successCount), options.Category, strings.Join(gameResults, "\n"), privateGameNote(c))
 
-	_, err = discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-		Content: responseContent,
-	})
-	if err != nil {
-		fmt.Println("Failed to send success followup message:", err)
-		return
-	}
-
-	fmt.Printf("User %s recorded %d games with leader %s\n", username, successCount, leader)
+	c.Logger().Info("Games recorded", "username", user.Username, "count", successCount, "leader", options.Leader)
+	return nil
 }
-
-// func basicCommand(discord *discordgo.Session, i *discordgo.InteractionCreate) {
-// 	fmt.Println("Command executed")
-// 	discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-// 		Type: discordgo.InteractionResponseChannelMessageWithSource,
-// 		Data: &discordgo.InteractionResponseData{
-// 			Content: "Hey there! Congratulations, you just executed your first slash command",
-// 		},
-// 	})
-// }
-
-// func discordPrefixedCommands(discord *discordgo.Session, message *discordgo.MessageCreate) {
-// 	if message.Content[:1] != commandPrefix || message.Content == "" {
-// 		return
-// 	}
-
-// 	switch message.Content[1:] {
-// 	case "helloworld":
-// 		discord.ChannelMessageSend(message.ChannelID, "Hello, world!")
-// 	default:
-// 		discord.ChannelMessageSend(message.ChannelID, "Unknown command")
-// 	}
-// }
@@ -1,6 +1,11 @@
 package main
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"context"
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+)
 
 const (
 	DISCORD_ALLOW = 1
@@ -8,11 +13,96 @@ const (
 	DISCORD_ROLE  = "@everyone"
 )
 
+// interactionUser returns whoever triggered the interaction. Discord sets Member in
+// servers and User in DMs and user-installed contexts, so handlers must not read
+// i.Member directly.
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	if i.User != nil {
+		return i.User
+	}
+	return &discordgo.User{}
+}
+
+// getOrCreateInteractionUser returns the stored user for whoever triggered the interaction
+func getOrCreateInteractionUser(ctx context.Context, i *discordgo.InteractionCreate) (*User, error) {
+	invoker := interactionUser(i)
+	return GetOrCreateUser(ctx, invoker.ID, invoker.Username, invoker.Discriminator)
+}
+
+// timeoutMessage replaces the error reply when an interaction ran past its deadline
+const timeoutMessage = "⏱️ That took longer than expected, so I gave up. Please try again in a moment."
+
+// errorReply records the interaction's outcome for the command metrics and returns
+// the message to show the user: the timeout message if the interaction's deadline
+// has passed, otherwise content
+func errorReply(ctx context.Context, i *discordgo.InteractionCreate, content string) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		markInteractionOutcome(i, "timeout")
+		return timeoutMessage
+	}
+	markInteractionOutcome(i, "error")
+	return content
+}
+
+// sendErrorFollowup sends an error message as a followup to a deferred interaction
+// and marks the interaction as failed in the command metrics. If the interaction's
+// deadline has passed, the user is told it timed out instead.
+func sendErrorFollowup(ctx context.Context, discord *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	content = errorReply(ctx, i, content)
+
+	_, err := discord.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+	})
+	if err != nil {
+		interactionLogger(i).Error("Failed to send error followup message", "error", err)
+	}
+}
+
+// respondEphemeralError replies privately with an error and marks the interaction as
+// failed in the command metrics, or as timed out if its deadline has passed
+func respondEphemeralError(ctx context.Context, discord *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	respondEphemeral(discord, i, errorReply(ctx, i, content))
+}
+
+// respondEphemeral replies to an interaction with a message only the invoking user can see
+func respondEphemeral(discord *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		interactionLogger(i).Error("Failed to send ephemeral response", "error", err)
+	}
+}
+
+// resolveUserOption returns the full user for a user-typed command option
+func resolveUserOption(i *discordgo.InteractionCreate, option *discordgo.ApplicationCommandInteractionDataOption) *discordgo.User {
+	userID, ok := option.Value.(string)
+	if !ok {
+		return nil
+	}
+
+	resolved := i.ApplicationCommandData().Resolved
+	if resolved != nil {
+		if user, ok := resolved.Users[userID]; ok {
+			return user
+		}
+	}
+
+	return &discordgo.User{ID: userID}
+}
+
 func createDiscordTextChannel(channel_name string, discord *discordgo.Session, guildID string, role_id string) (string, error) {
 	channel, err := discord.GuildChannelCreateComplex(guildID, discordgo.GuildChannelCreateData{
 		Name:     channel_name,
 		Type:     discordgo.ChannelTypeGuildText,
-		ParentID: getEnv("CATEGORY_ID"),
+		ParentID: AppConfig.CategoryID,
 		PermissionOverwrites: []*discordgo.PermissionOverwrite{
 			{
 				ID:   guildID,
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// gameGroupComponentPrefix prefixes the custom IDs of the join, leave and archive
+	// buttons on game groups
+	gameGroupComponentPrefix = "game-group"
+	// maxGameGroupNameLength is the longest game name the game_groups table can hold
+	maxGameGroupNameLength = 100
+	// gameArchiveCheckInterval is how often the auto-archiver looks for idle channels
+	gameArchiveCheckInterval = time.Hour
+	// gameArchiveCheckTimeout bounds the database and Discord work of one check
+	gameArchiveCheckTimeout = 5 * time.Minute
+)
+
+// manageChannelsPermission lets members archive and delete game groups they didn't create
+var manageChannelsPermission int64 = discordgo.PermissionManageChannels
+
+// suggestGameGroups suggests the game groups of the server the interaction came from
+func suggestGameGroups(ctx context.Context, i *discordgo.InteractionCreate) ([]string, error) {
+	return GetGuildGameGroupNames(ctx, i.GuildID)
+}
+
+// isDiscordNotFound reports whether a Discord API call failed because its target,
+// such as a channel or role, no longer exists
+func isDiscordNotFound(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+}
+
+// gameGroupButton builds one of the buttons acting on a game group
+func gameGroupButton(label string, style discordgo.ButtonStyle, action string, groupID int) discordgo.Button {
+	return discordgo.Button{
+		Label:    label,
+		Style:    style,
+		CustomID: fmt.Sprintf("%s:%s:%d", gameGroupComponentPrefix, action, groupID),
+	}
+}
+
+type createGameOptions struct {
+	Game string `option:"game" description:"The game to create a channel for" required:"true"`
+}
+
+func (o *createGameOptions) validate() error {
+	if o.Game == "" || len(o.Game) > maxGameGroupNameLength {
+		return fmt.Errorf("❌ Game names must be 1 to %d characters long.", maxGameGroupNameLength)
+	}
+	return nil
+}
+
+var createGameCommand = &slashCommand[createGameOptions]{
+	Name:             "create-game",
+	Description:      "Create special channel for a game",
+	Contexts:         guildOnlyContexts,
+	IntegrationTypes: guildInstallTypes,
+	Run:              runCreateGame,
+}
+
+func runCreateGame(ctx context.Context, c *commandContext, options *createGameOptions) error {
+	const failure = "❌ Failed to create the game channel. Please try again later."
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	_, err = GetGameGroup(ctx, c.GuildID(), options.Game)
+	if err == nil {
+		return userError("❌ There's already a game called **%s**. Join it with `/game join`.", options.Game)
+	}
+	if !errors.Is(err, ErrGameGroupNotFound) {
+		return commandFailed(failure, err)
+	}
+
+	role, err := createDiscordRole(options.Game, c.Discord, c.GuildID())
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to create role: %w", err))
+	}
+
+	channelID, err := createDiscordTextChannel(options.Game, c.Discord, c.GuildID(), role.ID)
+	if err != nil {
+		deleteGameGroupResources(c.Discord, c.GuildID(), role.ID, "")
+		return commandFailed(failure, fmt.Errorf("failed to create channel: %w", err))
+	}
+
+	group, err := CreateGameGroup(ctx, c.GuildID(), options.Game, role.ID, channelID, user.ID)
+	if err != nil {
+		deleteGameGroupResources(c.Discord, c.GuildID(), role.ID, channelID)
+		if errors.Is(err, ErrGameGroupExists) {
+			return userError("❌ There's already a game called **%s**. Join it with `/game join`.", options.Game)
+		}
+		return commandFailed(failure, fmt.Errorf("failed to create game group: %w", err))
+	}
+
+	err = c.Discord.GuildMemberRoleAdd(c.GuildID(), c.Invoker().ID, role.ID)
+	if err != nil {
+		c.Logger().Warn("Failed to give the creator the game role", "game_group_id", group.ID, "error", err)
+	}
+
+	_, err = c.Discord.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("🎮 Welcome to **%s**! Only members with the game's role can see this channel. "+
+			"It's archived after %d hours without messages.", group.Name, AppConfig.GameArchiveAfterHours),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					gameGroupButton("Leave", discordgo.SecondaryButton, "leave", group.ID),
+					gameGroupButton("Archive", discordgo.DangerButton, "archive", group.ID),
+				},
+			},
+		},
+	})
+	if err != nil {
+		c.Logger().Warn("Failed to post game channel controls", "game_group_id", group.ID, "error", err)
+	}
+
+	c.Send(&discordgo.WebhookParams{
+		Content: fmt.Sprintf("🎮 Created **%s** in <#%s>. Press Join or use `/game join` to get access.", group.Name, channelID),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					gameGroupButton("Join", discordgo.SuccessButton, "join", group.ID),
+				},
+			},
+		},
+	})
+
+	c.Logger().Info("Game group created", "game_group_id", group.ID, "channel_id", channelID)
+	return nil
+}
+
+// deleteGameGroupResources deletes a game group's channel and role from Discord,
+// skipping empty IDs and ones that are already gone
+func deleteGameGroupResources(discord *discordgo.Session, guildID, roleID, channelID string) error {
+	if channelID != "" {
+		_, err := discord.ChannelDelete(channelID)
+		if err != nil && !isDiscordNotFound(err) {
+			return fmt.Errorf("failed to delete channel: %w", err)
+		}
+	}
+	if roleID != "" {
+		err := discord.GuildRoleDelete(guildID, roleID)
+		if err != nil && !isDiscordNotFound(err) {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+	}
+	return nil
+}
+
+// joinGameGroup gives a member a game group's role
+func joinGameGroup(ctx context.Context, discord *discordgo.Session, group *GameGroup, discordID string) (string, error) {
+	err := discord.GuildMemberRoleAdd(group.GuildID, discordID, group.RoleID)
+	if err != nil {
+		return "", commandFailed("❌ Failed to join the game. Please try again later.", fmt.Errorf("failed to add game role: %w", err))
+	}
+
+	if group.Status == GameGroupStatusActive {
+		err = TouchGameGroup(ctx, group.ID)
+		if err != nil {
+			slog.Warn("Failed to record game group activity", "game_group_id", group.ID, "error", err)
+		}
+		return fmt.Sprintf("✅ You joined **%s**: <#%s>", group.Name, group.ChannelID), nil
+	}
+	return fmt.Sprintf("✅ You joined **%s**. It's archived, so <#%s> is read-only.", group.Name, group.ChannelID), nil
+}
+
+// leaveGameGroup takes a game group's role away from a member
+func leaveGameGroup(discord *discordgo.Session, group *GameGroup, discordID string) (string, error) {
+	err := discord.GuildMemberRoleRemove(group.GuildID, discordID, group.RoleID)
+	if err != nil {
+		return "", commandFailed("❌ Failed to leave the game. Please try again later.", fmt.Errorf("failed to remove game role: %w", err))
+	}
+	return fmt.Sprintf("👋 You left **%s**.", group.Name), nil
+}
+
+// archiveGameGroup makes a game group's channel read-only for its members and marks
+// the group archived
+func archiveGameGroup(ctx context.Context, discord *discordgo.Session, group *GameGroup, reason string) (string, error) {
+	const failure = "❌ Failed to archive the game. Please try again later."
+	if group.Status != GameGroupStatusActive {
+		return "", userError("❌ **%s** is already archived.", group.Name)
+	}
+
+	err := discord.ChannelPermissionSet(group.ChannelID, group.RoleID, discordgo.PermissionOverwriteTypeRole,
+		discordgo.PermissionViewChannel|discordgo.PermissionReadMessageHistory, discordgo.PermissionSendMessages)
+	if err != nil {
+		return "", commandFailed(failure, fmt.Errorf("failed to make channel read-only: %w", err))
+	}
+
+	_, err = ArchiveGameGroup(ctx, group.ID)
+	if err != nil {
+		if errors.Is(err, ErrGameGroupNotActive) {
+			return "", userError("❌ **%s** is already archived.", group.Name)
+		}
+		return "", commandFailed(failure, fmt.Errorf("failed to archive game group: %w", err))
+	}
+
+	_, err = discord.ChannelMessageSend(group.ChannelID, fmt.Sprintf("🗄️ This game was archived %s. The channel is now read-only.", reason))
+	if err != nil {
+		slog.Warn("Failed to post archive notice", "game_group_id", group.ID, "error", err)
+	}
+
+	return fmt.Sprintf("🗄️ Archived **%s**.", group.Name), nil
+}
+
+// canManageGameGroup reports whether the interaction's user may archive or delete a
+// game group: its creator, or anyone who can manage channels
+func canManageGameGroup(ctx context.Context, i *discordgo.InteractionCreate, group *GameGroup) bool {
+	if hasPermissions(i, manageChannelsPermission) {
+		return true
+	}
+	if group.CreatorUserID == nil {
+		return false
+	}
+	user, err := GetUserByDiscordID(ctx, interactionUser(i).ID)
+	return err == nil && user.ID == *group.CreatorUserID
+}
+
+var gameCommand = &commandGroup{
+	Name:             "game",
+	Description:      "Join, leave and clean up game channels",
+	Contexts:         guildOnlyContexts,
+	IntegrationTypes: guildInstallTypes,
+	Subcommands: []subcommandSpec{
+		gameJoinCommand,
+		gameLeaveCommand,
+		gameArchiveCommand,
+		gameDeleteCommand,
+	},
+}
+
+type gameGroupOptions struct {
+	Game string `option:"game" description:"The game; defaults to the game channel you're in" autocomplete:"game-group"`
+}
+
+// resolveGameGroup finds the game named in options, or the one whose channel the
+// command was run in
+func resolveGameGroup(ctx context.Context, c *commandContext, options *gameGroupOptions) (*GameGroup, error) {
+	key := options.Game
+	if key == "" {
+		key = c.Interaction.ChannelID
+	}
+
+	group, err := GetGameGroup(ctx, c.GuildID(), key)
+	if err != nil {
+		if !errors.Is(err, ErrGameGroupNotFound) {
+			return nil, commandFailed("❌ Failed to load the game. Please try again later.", err)
+		}
+		if options.Game == "" {
+			return nil, userError("❌ Pick a game, or run this in a game's channel.")
+		}
+		return nil, userError("❌ `%s` isn't a game here.", options.Game)
+	}
+	return group, nil
+}
+
+var gameJoinCommand = &slashCommand[gameGroupOptions]{
+	Name:        "join",
+	Description: "Get access to a game's channel",
+	Ephemeral:   true,
+	Run:         runGameJoin,
+}
+
+func runGameJoin(ctx context.Context, c *commandContext, options *gameGroupOptions) error {
+	group, err := resolveGameGroup(ctx, c, options)
+	if err != nil {
+		return err
+	}
+
+	message, err := joinGameGroup(ctx, c.Discord, group, c.Invoker().ID)
+	if err != nil {
+		return err
+	}
+	c.Reply(message)
+	return nil
+}
+
+var gameLeaveCommand = &slashCommand[gameGroupOptions]{
+	Name:        "leave",
+	Description: "Leave a game's channel",
+	Ephemeral:   true,
+	Run:         runGameLeave,
+}
+
+func runGameLeave(ctx context.Context, c *commandContext, options *gameGroupOptions) error {
+	group, err := resolveGameGroup(ctx, c, options)
+	if err != nil {
+		return err
+	}
+
+	message, err := leaveGameGroup(c.Discord, group, c.Invoker().ID)
+	if err != nil {
+		return err
+	}
+	c.Reply(message)
+	return nil
+}
+
+var gameArchiveCommand = &slashCommand[gameGroupOptions]{
+	Name:        "archive",
+	Description: "Make a game's channel read-only. Only its creator or channel managers can.",
+	Ephemeral:   true,
+	Run:         runGameArchive,
+}
+
+func runGameArchive(ctx context.Context, c *commandContext, options *gameGroupOptions) error {
+	group, err := resolveGameGroup(ctx, c, options)
+	if err != nil {
+		return err
+	}
+	if !canManageGameGroup(ctx, c.Interaction, group) {
+		return userError("❌ Only the game's creator or members who can manage channels can archive it.")
+	}
+
+	message, err := archiveGameGroup(ctx, c.Discord, group, fmt.Sprintf("by <@%s>", c.Invoker().ID))
+	if err != nil {
+		return err
+	}
+	c.Reply(message)
+
+	c.Logger().Info("Game group archived", "game_group_id", group.ID)
+	return nil
+}
+
+var gameDeleteCommand = &slashCommand[gameGroupOptions]{
+	Name:        "delete",
+	Description: "Delete a game's channel and role. Only its creator or channel managers can.",
+	Ephemeral:   true,
+	Run:         runGameDelete,
+}
+
+func runGameDelete(ctx context.Context, c *commandContext, options *gameGroupOptions) error {
+	const failure = "❌ Failed to delete the game. Please try again later."
+
+	group, err := resolveGameGroup(ctx, c, options)
+	if err != nil {
+		return err
+	}
+	if !canManageGameGroup(ctx, c.Interaction, group) {
+		return userError("❌ Only the game's creator or members who can manage channels can delete it.")
+	}
+
+	err = deleteGameGroupResources(c.Discord, group.GuildID, group.RoleID, group.ChannelID)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	err = DeleteGameGroup(ctx, group.ID)
+	if err != nil && !errors.Is(err, ErrGameGroupNotFound) {
+		return commandFailed(failure, fmt.Errorf("failed to delete game group: %w", err))
+	}
+
+	// If this ran in the game's own channel, the reply has nowhere to go
+	if c.Interaction.ChannelID != group.ChannelID {
+		c.Replyf("🗑️ Deleted **%s** and its channel and role.", group.Name)
+	}
+
+	c.Logger().Info("Game group deleted", "game_group_id", group.ID)
+	return nil
+}
+
+// gameGroupComponent handles the join, leave and archive buttons on game groups
+func gameGroupComponent(ctx context.Context, discord *discordgo.Session, i *discordgo.InteractionCreate) {
+	interactionLogger(i).Info("Game group component executed")
+
+	// Custom IDs look like game-group:<action>:<game group id>
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if len(parts) != 3 {
+		respondEphemeralError(ctx, discord, i, "❌ Unknown action.")
+		return
+	}
+	groupID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		respondEphemeralError(ctx, discord, i, "❌ Unknown action.")
+		return
+	}
+
+	group, err := GetGameGroupByID(ctx, groupID)
+	if err != nil {
+		interactionLogger(i).Error("Failed to get game group", "error", err)
+		respondEphemeralError(ctx, discord, i, "❌ This game no longer exists.")
+		return
+	}
+
+	var message string
+	switch parts[1] {
+	case "join":
+		message, err = joinGameGroup(ctx, discord, group, interactionUser(i).ID)
+	case "leave":
+		message, err = leaveGameGroup(discord, group, interactionUser(i).ID)
+	case "archive":
+		if !canManageGameGroup(ctx, i, group) {
+			err = userError("❌ Only the game's creator or members who can manage channels can archive it.")
+			break
+		}
+		message, err = archiveGameGroup(ctx, discord, group, fmt.Sprintf("by <@%s>", interactionUser(i).ID))
+	default:
+		err = userError("❌ Unknown action.")
+	}
+
+	if err != nil {
+		var cmdErr *commandError
+		if !errors.As(err, &cmdErr) {
+			cmdErr = &commandError{message: genericErrorMessage, err: err}
+		}
+		if cmdErr.err != nil {
+			interactionLogger(i).Error("Game group action failed", "error", cmdErr.err)
+		}
+		respondEphemeralError(ctx, discord, i, cmdErr.message)
+		return
+	}
+
+	respondEphemeral(discord, i, message)
+}
+
+// archiveIdleGameGroups archives game groups whose channels have had no messages, and
+// no one joining, for GAME_ARCHIVE_AFTER_HOURS. It checks every hour until ctx is
+// cancelled. Groups whose channel was deleted by hand are cleaned up.
+func archiveIdleGameGroups(ctx context.Context, discord *discordgo.Session) {
+	ticker := time.NewTicker(gameArchiveCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, gameArchiveCheckTimeout)
+		archiveIdleGameGroupsOnce(checkCtx, discord)
+		cancel()
+	}
+}
+
+// archiveIdleGameGroupsOnce runs one auto-archiver check
+func archiveIdleGameGroupsOnce(ctx context.Context, discord *discordgo.Session) {
+	groups, err := GetActiveGameGroups(ctx)
+	if err != nil {
+		slog.Error("Failed to get active game groups", "error", err)
+		return
+	}
+
+	for _, group := range groups {
+		logger := slog.With("game_group_id", group.ID, "guild_id", group.GuildID)
+		auditCtx := withSystemAuditSource(ctx, group.GuildID)
+
+		channel, err := discord.Channel(group.ChannelID)
+		if isDiscordNotFound(err) {
+			err = deleteGameGroupResources(discord, group.GuildID, group.RoleID, "")
+			if err == nil {
+				err = DeleteGameGroup(auditCtx, group.ID)
+			}
+			if err != nil {
+				logger.Error("Failed to clean up game group with a deleted channel", "error", err)
+				continue
+			}
+			logger.Info("Game group channel was deleted, removed the group")
+			continue
+		}
+		if err != nil {
+			logger.Error("Failed to get game group channel", "error", err)
+			continue
+		}
+
+		lastActivity := group.LastActivityAt
+		if channel.LastMessageID != "" {
+			lastMessage, err := discordgo.SnowflakeTimestamp(channel.LastMessageID)
+			if err == nil && lastMessage.After(lastActivity) {
+				lastActivity = lastMessage
+			}
+		}
+		if time.Since(lastActivity) < AppConfig.GameArchiveAfter() {
+			continue
+		}
+
+		_, err = archiveGameGroup(auditCtx, discord, group, fmt.Sprintf("after %d hours without activity", AppConfig.GameArchiveAfterHours))
+		if err != nil {
+			logger.Error("Failed to auto-archive game group", "error", err)
+			continue
+		}
+		logger.Info("Game group auto-archived", "last_activity", lastActivity)
+	}
+}
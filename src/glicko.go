@@ -0,0 +1,125 @@
+package main
+
+import "math"
+
+// Glicko-2 system constants. Ratings are stored on the familiar Glicko scale
+// (1500 ± 350) and converted to the Glicko-2 scale for the update itself.
+const (
+	glickoDefaultRating     = 1500.0
+	glickoDefaultRD         = 350.0
+	glickoDefaultVolatility = 0.06
+	glickoTau               = 0.5
+	glickoScale             = 173.7178
+	glickoConvergence       = 0.000001
+)
+
+// GlickoRating is a player's Glicko-2 rating, rating deviation and volatility
+type GlickoRating struct {
+	Rating     float64 `json:"rating"`
+	RD         float64 `json:"rd"`
+	Volatility float64 `json:"volatility"`
+}
+
+// GlickoResult is a single game result used to update a rating. Score is 1 for a
+// win, 0.5 for a draw and 0 for a loss.
+type GlickoResult struct {
+	Opponent GlickoRating
+	Score    float64
+}
+
+// NewGlickoRating returns the rating given to a player with no rated games
+func NewGlickoRating() GlickoRating {
+	return GlickoRating{
+		Rating:     glickoDefaultRating,
+		RD:         glickoDefaultRD,
+		Volatility: glickoDefaultVolatility,
+	}
+}
+
+// Update returns the rating after a rating period containing the given results.
+// An empty period only increases the rating deviation.
+func (r GlickoRating) Update(results []GlickoResult) GlickoRating {
+	mu := (r.Rating - glickoDefaultRating) / glickoScale
+	phi := r.RD / glickoScale
+
+	if len(results) == 0 {
+		phiStar := math.Sqrt(phi*phi + r.Volatility*r.Volatility)
+		return GlickoRating{
+			Rating:     r.Rating,
+			RD:         math.Min(phiStar*glickoScale, glickoDefaultRD),
+			Volatility: r.Volatility,
+		}
+	}
+
+	// Estimated variance and improvement from the period's results
+	var vInverse, deltaSum float64
+	for _, result := range results {
+		muJ := (result.Opponent.Rating - glickoDefaultRating) / glickoScale
+		phiJ := result.Opponent.RD / glickoScale
+		g := glickoG(phiJ)
+		e := glickoE(mu, muJ, g)
+		vInverse += g * g * e * (1 - e)
+		deltaSum += g * (result.Score - e)
+	}
+	v := 1 / vInverse
+	delta := v * deltaSum
+
+	sigma := glickoVolatility(phi, r.Volatility, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*deltaSum
+
+	return GlickoRating{
+		Rating:     newMu*glickoScale + glickoDefaultRating,
+		RD:         newPhi * glickoScale,
+		Volatility: sigma,
+	}
+}
+
+// glickoG reduces the impact of a game according to the opponent's deviation
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// glickoE is the expected score against an opponent
+func glickoE(mu, muJ, g float64) float64 {
+	return 1 / (1 + math.Exp(-g*(mu-muJ)))
+}
+
+// glickoVolatility computes the new volatility using the Illinois algorithm
+// described in step 5 of the Glicko-2 paper
+func glickoVolatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(glickoTau*glickoTau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*glickoTau) < 0 {
+			k++
+		}
+		B = a - k*glickoTau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > glickoConvergence {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGlickoRatingUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rating  GlickoRating
+		results []GlickoResult
+		want    GlickoRating
+	}{
+		{
+			// The worked example from Glickman's "Example of the Glicko-2 system"
+			name:   "paper example",
+			rating: GlickoRating{Rating: 1500, RD: 200, Volatility: 0.06},
+			results: []GlickoResult{
+				{Opponent: GlickoRating{Rating: 1400, RD: 30}, Score: 1},
+				{Opponent: GlickoRating{Rating: 1550, RD: 100}, Score: 0},
+				{Opponent: GlickoRating{Rating: 1700, RD: 300}, Score: 0},
+			},
+			want: GlickoRating{Rating: 1464.06, RD: 151.52, Volatility: 0.05999},
+		},
+		{
+			name:   "empty period only widens the deviation",
+			rating: GlickoRating{Rating: 1650, RD: 100, Volatility: 0.06},
+			want:   GlickoRating{Rating: 1650, RD: 100.54, Volatility: 0.06},
+		},
+		{
+			name:   "empty period caps the deviation",
+			rating: NewGlickoRating(),
+			want:   NewGlickoRating(),
+		},
+		{
+			name:    "draw between equal new players changes nothing but the deviation",
+			rating:  NewGlickoRating(),
+			results: []GlickoResult{{Opponent: NewGlickoRating(), Score: 0.5}},
+			want:    GlickoRating{Rating: 1500, RD: 290.32, Volatility: 0.06},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rating.Update(tt.results)
+			if math.Abs(got.Rating-tt.want.Rating) > 0.01 {
+				t.Errorf("Rating = %.4f, want %.2f", got.Rating, tt.want.Rating)
+			}
+			if math.Abs(got.RD-tt.want.RD) > 0.01 {
+				t.Errorf("RD = %.4f, want %.2f", got.RD, tt.want.RD)
+			}
+			if math.Abs(got.Volatility-tt.want.Volatility) > 0.00001 {
+				t.Errorf("Volatility = %.6f, want %.5f", got.Volatility, tt.want.Volatility)
+			}
+		})
+	}
+}
+
+func TestGlickoRatingUpdateDirection(t *testing.T) {
+	opponent := GlickoRating{Rating: 1500, RD: 80, Volatility: 0.06}
+	tests := []struct {
+		name  string
+		score float64
+		check func(before, after float64) bool
+	}{
+		{"win raises the rating", 1, func(before, after float64) bool { return after > before }},
+		{"loss lowers the rating", 0, func(before, after float64) bool { return after < before }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := NewGlickoRating()
+			after := before.Update([]GlickoResult{{Opponent: opponent, Score: tt.score}})
+			if !tt.check(before.Rating, after.Rating) {
+				t.Errorf("rating went from %.2f to %.2f", before.Rating, after.Rating)
+			}
+			if after.RD >= before.RD {
+				t.Errorf("RD = %.2f, want less than %.2f after a game", after.RD, before.RD)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// readinessDBTimeout bounds the database ping made by /readyz
+const readinessDBTimeout = 2 * time.Second
+
+// discordGatewayConnected is true while the Discord gateway session is up
+var discordGatewayConnected atomic.Bool
+
+// trackGatewayState keeps discordGatewayConnected in sync with the gateway
+func trackGatewayState(discord *discordgo.Session) {
+	discord.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
+		discordGatewayConnected.Store(true)
+	})
+	discord.AddHandler(func(s *discordgo.Session, r *discordgo.Resumed) {
+		discordGatewayConnected.Store(true)
+	})
+	discord.AddHandler(func(s *discordgo.Session, d *discordgo.Disconnect) {
+		discordGatewayConnected.Store(false)
+	})
+}
+
+// registerHealthRoutes adds the liveness, readiness and metrics endpoints
+func registerHealthRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", healthzHandler)
+	mux.HandleFunc("GET /readyz", readyzHandler)
+	mux.HandleFunc("GET /metrics", metricsHandler)
+}
+
+// healthzHandler reports that the process is up
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyzHandler reports whether the bot can serve interactions: the database
+// answers a ping and the Discord gateway is connected
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]string{"database": "ok", "discord": "ok"}
+	status := http.StatusOK
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessDBTimeout)
+	defer cancel()
+	err := DB.PingContext(ctx)
+	if err != nil {
+		checks["database"] = err.Error()
+		status = http.StatusServiceUnavailable
+	}
+
+	if !discordGatewayConnected.Load() {
+		checks["discord"] = "gateway not connected"
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, checks)
+}
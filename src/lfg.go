@@ -0,0 +1,460 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// lfgComponentPrefix prefixes the custom ID of the record button in match channels
+	lfgComponentPrefix = "lfg"
+	// lfgResultModalPrefix prefixes the custom ID of the modal the record button opens
+	lfgResultModalPrefix = "lfg-result"
+	// maxLeaderLength is the longest leader name the lfg_queue table can hold
+	maxLeaderLength = 100
+	// maxLFGOpponents is the most opponent leaders a queue entry can ask for
+	maxLFGOpponents = 10
+	// lfgMatchLifetime is how long a match channel stays before it's removed
+	lfgMatchLifetime = 24 * time.Hour
+	// lfgCleanupInterval is how often old match channels are looked for
+	lfgCleanupInterval = time.Hour
+	// lfgCleanupTimeout bounds the database and Discord work of one cleanup
+	lfgCleanupTimeout = 5 * time.Minute
+)
+
+var lfgCommand = &commandGroup{
+	Name:             "lfg",
+	Description:      "Find another member to practice with",
+	Contexts:         guildOnlyContexts,
+	IntegrationTypes: guildInstallTypes,
+	Subcommands: []subcommandSpec{
+		lfgJoinCommand,
+		lfgLeaveCommand,
+		lfgListCommand,
+	},
+}
+
+type lfgJoinOptions struct {
+	Leader    string `option:"leader" description:"The leader you'll play" required:"true"`
+	Opponents string `option:"opponents" description:"Opponent leaders you want to practice against, separated by commas; leave out for any"`
+	Category  string `option:"category" description:"Game category (Casual, Ranked, Locals, Tournament, etc.)" autocomplete:"category"`
+	Hours     int    `option:"hours" description:"How many hours you're available for" default:"2" min:"1" max:"12"`
+}
+
+// wantedOpponents splits the opponents option into leader names
+func (o *lfgJoinOptions) wantedOpponents() []string {
+	var opponents []string
+	for _, opponent := range strings.Split(o.Opponents, ",") {
+		opponent = strings.TrimSpace(opponent)
+		if opponent != "" {
+			opponents = append(opponents, opponent)
+		}
+	}
+	return opponents
+}
+
+func (o *lfgJoinOptions) validate() error {
+	if len(o.Leader) > maxLeaderLength {
+		return fmt.Errorf("❌ Leader names can be at most %d characters long.", maxLeaderLength)
+	}
+	if len(o.wantedOpponents()) > maxLFGOpponents {
+		return fmt.Errorf("❌ You can ask for at most %d opponent leaders.", maxLFGOpponents)
+	}
+	return nil
+}
+
+var lfgJoinCommand = &slashCommand[lfgJoinOptions]{
+	Name:        "join",
+	Description: "Join the practice queue and get paired with a compatible member",
+	Ephemeral:   true,
+	Run:         runLFGJoin,
+}
+
+// formatWantedOpponents describes the opponents a queue entry is looking for
+func formatWantedOpponents(entry *LFGEntry) string {
+	if len(entry.WantedOpponents) == 0 {
+		return "any leader"
+	}
+	return strings.Join(entry.WantedOpponents, ", ")
+}
+
+func runLFGJoin(ctx context.Context, c *commandContext, options *lfgJoinOptions) error {
+	const failure = "❌ Failed to join the queue. Please try again later."
+
+	category, err := resolveGuildCategory(ctx, c, options.Category)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	availableUntil := time.Now().Add(time.Duration(options.Hours) * time.Hour)
+	entry, match, err := JoinLFGQueue(ctx, user.ID, c.GuildID(), options.Leader, options.wantedOpponents(), category, availableUntil)
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to join lfg queue: %w", err))
+	}
+
+	if match == nil {
+		c.Replyf("🔎 You're in the **%s** queue as **%s**, looking for %s, until <t:%d:t>. I'll ping you when someone compatible joins. Leave with `/lfg leave`.",
+			category, entry.Leader, formatWantedOpponents(entry), availableUntil.Unix())
+		c.Logger().Info("Joined lfg queue", "lfg_entry_id", entry.ID)
+		return nil
+	}
+
+	channelID, err := openLFGMatch(ctx, c.Discord, match)
+	if err != nil {
+		// Put both back in the queue so the partner doesn't lose their place
+		cancelErr := CancelLFGMatch(ctx, match.ID)
+		if cancelErr != nil {
+			c.Logger().Error("Failed to cancel lfg match", "lfg_match_id", match.ID, "error", cancelErr)
+		}
+		return commandFailed(failure, fmt.Errorf("failed to open lfg match: %w", err))
+	}
+
+	partner := match.Opponent(user.ID)
+	c.Replyf("🎯 Matched with <@%s> (**%s**)! Head to <#%s>.", partner.DiscordID, partner.Leader, channelID)
+
+	c.Logger().Info("Lfg match opened", "lfg_match_id", match.ID, "channel_id", channelID)
+	return nil
+}
+
+// openLFGMatch creates a match's private channel and role, gives the role to both
+// players and pings them there with a button to record the result
+func openLFGMatch(ctx context.Context, discord *discordgo.Session, match *LFGMatch) (string, error) {
+	name := fmt.Sprintf("lfg-%d", match.ID)
+	role, err := createDiscordRole(name, discord, match.GuildID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create role: %w", err)
+	}
+
+	channelID, err := createDiscordTextChannel(name, discord, match.GuildID, role.ID)
+	if err != nil {
+		deleteGameGroupResources(discord, match.GuildID, role.ID, "")
+		return "", fmt.Errorf("failed to create channel: %w", err)
+	}
+
+	// Saved right away so the cleanup job still removes the channel if deleting it here fails
+	err = SetLFGMatchChannel(ctx, match.ID, channelID, role.ID)
+	if err != nil {
+		deleteGameGroupResources(discord, match.GuildID, role.ID, channelID)
+		return "", err
+	}
+
+	var mentions []string
+	var players []string
+	for _, entry := range match.Entries {
+		err = discord.GuildMemberRoleAdd(match.GuildID, entry.DiscordID, role.ID)
+		if err != nil {
+			deleteGameGroupResources(discord, match.GuildID, role.ID, channelID)
+			return "", fmt.Errorf("failed to add lfg role: %w", err)
+		}
+		mentions = append(mentions, entry.DiscordID)
+		players = append(players, fmt.Sprintf("<@%s> (**%s**)", entry.DiscordID, entry.Leader))
+	}
+
+	_, err = discord.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("🎯 **Practice match!** %s\n📂 Category: **%s**\n\nGood luck! When you're done, either of you can press **Record result** and the other confirms it. "+
+			"This channel is removed after %d hours.", strings.Join(players, " vs "), match.Category, int(lfgMatchLifetime.Hours())),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Record result",
+						Style:    discordgo.PrimaryButton,
+						CustomID: fmt.Sprintf("%s:record:%d", lfgComponentPrefix, match.ID),
+					},
+				},
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{Users: mentions},
+	})
+	if err != nil {
+		slog.Warn("Failed to ping lfg match players", "lfg_match_id", match.ID, "error", err)
+	}
+
+	return channelID, nil
+}
+
+var lfgLeaveCommand = &slashCommand[noOptions]{
+	Name:        "leave",
+	Description: "Leave the practice queue",
+	Ephemeral:   true,
+	Run:         runLFGLeave,
+}
+
+func runLFGLeave(ctx context.Context, c *commandContext, options *noOptions) error {
+	const failure = "❌ Failed to leave the queue. Please try again later."
+
+	user, err := GetUserByDiscordID(ctx, c.Invoker().ID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			c.Reply("🔎 You aren't in the queue.")
+			return nil
+		}
+		return commandFailed(failure, err)
+	}
+
+	left, err := LeaveLFGQueue(ctx, user.ID, c.GuildID())
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to leave lfg queue: %w", err))
+	}
+	if !left {
+		c.Reply("🔎 You aren't in the queue.")
+		return nil
+	}
+
+	c.Reply("👋 You left the queue.")
+	return nil
+}
+
+var lfgListCommand = &slashCommand[noOptions]{
+	Name:        "list",
+	Description: "Show who's waiting in the practice queue",
+	Ephemeral:   true,
+	Run:         runLFGList,
+}
+
+func runLFGList(ctx context.Context, c *commandContext, options *noOptions) error {
+	entries, err := GetLFGQueue(ctx, c.GuildID())
+	if err != nil {
+		return commandFailed("❌ Failed to load the queue. Please try again later.", err)
+	}
+
+	if len(entries) == 0 {
+		c.Reply("🔎 Nobody is waiting. Join with `/lfg join`.")
+		return nil
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("• <@%s> • **%s** • %s • looking for %s • until <t:%d:t>",
+			entry.DiscordID, entry.Leader, entry.Category, formatWantedOpponents(entry), entry.AvailableUntil.Unix()))
+	}
+
+	c.Send(&discordgo.WebhookParams{
+		Content:         fmt.Sprintf("🔎 **Practice queue** (%d waiting)\n\n%s", len(entries), strings.Join(lines, "\n")),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	return nil
+}
+
+// lfgMatchFromCustomID loads the match a record button or result modal belongs to and
+// checks that the interaction's user played in it
+func lfgMatchFromCustomID(ctx context.Context, i *discordgo.InteractionCreate, customID string) (*LFGMatch, *LFGEntry, error) {
+	// Custom IDs look like lfg:record:<match id> or lfg-result:<match id>
+	parts := strings.Split(customID, ":")
+	matchID, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return nil, nil, userError("❌ Unknown action.")
+	}
+
+	match, err := GetLFGMatch(ctx, matchID)
+	if err != nil {
+		if errors.Is(err, ErrLFGMatchNotFound) {
+			return nil, nil, userError("❌ This match no longer exists.")
+		}
+		return nil, nil, commandFailed("❌ Failed to load the match. Please try again later.", err)
+	}
+
+	user, err := GetUserByDiscordID(ctx, interactionUser(i).ID)
+	if err != nil {
+		return nil, nil, userError("❌ Only the match's players can record its result.")
+	}
+	entry := match.Entry(user.ID)
+	if entry == nil || match.Opponent(user.ID) == nil {
+		return nil, nil, userError("❌ Only the match's players can record its result.")
+	}
+	if match.GameResultID != nil {
+		return nil, nil, userError("❌ This match's result has already been recorded.")
+	}
+
+	return match, entry, nil
+}
+
+// respondLFGError replies privately with a handler error, logging it if it wasn't the
+// user's fault
+func respondLFGError(ctx context.Context, discord *discordgo.Session, i *discordgo.InteractionCreate, err error) {
+	var cmdErr *commandError
+	if !errors.As(err, &cmdErr) {
+		cmdErr = &commandError{message: genericErrorMessage, err: err}
+	}
+	if cmdErr.err != nil {
+		interactionLogger(i).Error("Lfg action failed", "error", cmdErr.err)
+	}
+	respondEphemeralError(ctx, discord, i, cmdErr.message)
+}
+
+// lfgComponent handles the record button in match channels by asking for the result
+func lfgComponent(ctx context.Context, discord *discordgo.Session, i *discordgo.InteractionCreate) {
+	interactionLogger(i).Info("Lfg component executed")
+
+	match, _, err := lfgMatchFromCustomID(ctx, i, i.MessageComponentData().CustomID)
+	if err != nil {
+		respondLFGError(ctx, discord, i, err)
+		return
+	}
+
+	err = discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("%s:%d", lfgResultModalPrefix, match.ID),
+			Title:    "Record the result",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "outcome",
+							Label:       "How did the game end for you?",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "win, loss, draw, concede…",
+							Required:    true,
+							MaxLength:   20,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "went_first",
+							Label:       "Did you go first?",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "yes or no",
+							Required:    true,
+							MaxLength:   3,
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		interactionLogger(i).Error("Failed to open lfg result modal", "error", err)
+	}
+}
+
+// modalValues returns a submitted modal's text inputs by custom ID
+func modalValues(data discordgo.ModalSubmitInteractionData) map[string]string {
+	values := map[string]string{}
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, input := range row.Components {
+			if textInput, ok := input.(*discordgo.TextInput); ok {
+				values[textInput.CustomID] = textInput.Value
+			}
+		}
+	}
+	return values
+}
+
+// lfgResultModal records a match's result as a player game against the other player,
+// who is asked to confirm it
+func lfgResultModal(ctx context.Context, discord *discordgo.Session, i *discordgo.InteractionCreate) {
+	interactionLogger(i).Info("Lfg result modal submitted")
+
+	data := i.ModalSubmitData()
+	match, entry, err := lfgMatchFromCustomID(ctx, i, data.CustomID)
+	if err != nil {
+		respondLFGError(ctx, discord, i, err)
+		return
+	}
+	opponent := match.Opponent(entry.UserID)
+
+	values := modalValues(data)
+	outcome, ok := ParseOutcome(values["outcome"])
+	if !ok {
+		respondEphemeralError(ctx, discord, i, fmt.Sprintf("❌ `%s` isn't an outcome. Use win, loss, draw, concede or penalty.", values["outcome"]))
+		return
+	}
+
+	var wentFirst bool
+	switch strings.ToLower(strings.TrimSpace(values["went_first"])) {
+	case "yes", "y":
+		wentFirst = true
+	case "no", "n":
+	default:
+		respondEphemeralError(ctx, discord, i, "❌ Answer yes or no to whether you went first.")
+		return
+	}
+
+	gameResult, err := RecordLFGMatchGame(ctx, match.ID, entry.UserID, opponent.UserID, match.GuildID, entry.Leader, opponent.Leader, match.Category, wentFirst, outcome)
+	if errors.Is(err, ErrLFGMatchRecorded) {
+		// Both players submitted at once and the other result won
+		respondLFGError(ctx, discord, i, userError("❌ This match's result has already been recorded."))
+		return
+	}
+	if err != nil {
+		respondLFGError(ctx, discord, i, commandFailed("❌ Failed to record the game. Please try again later.", fmt.Errorf("failed to record lfg match game: %w", err)))
+		return
+	}
+
+	err = discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         formatPlayerGameMessage(gameResult, entry.DiscordID, opponent.DiscordID),
+			Components:      playerGameButtons(gameResult.ID),
+			AllowedMentions: &discordgo.MessageAllowedMentions{Users: []string{opponent.DiscordID}},
+		},
+	})
+	if err != nil {
+		interactionLogger(i).Error("Failed to send lfg result message", "error", err)
+		return
+	}
+
+	interactionLogger(i).Info("Lfg match result recorded", "lfg_match_id", match.ID, "game_id", gameResult.ID)
+}
+
+// closeStaleLFGMatches removes match channels and roles once they're
+// lfgMatchLifetime old. It checks every hour until ctx is cancelled.
+func closeStaleLFGMatches(ctx context.Context, discord *discordgo.Session) {
+	ticker := time.NewTicker(lfgCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, lfgCleanupTimeout)
+		closeStaleLFGMatchesOnce(checkCtx, discord)
+		cancel()
+	}
+}
+
+// closeStaleLFGMatchesOnce runs one match channel cleanup
+func closeStaleLFGMatchesOnce(ctx context.Context, discord *discordgo.Session) {
+	matches, err := GetStaleLFGMatches(ctx, time.Now().Add(-lfgMatchLifetime))
+	if err != nil {
+		slog.Error("Failed to get stale lfg matches", "error", err)
+		return
+	}
+
+	for _, match := range matches {
+		logger := slog.With("lfg_match_id", match.ID, "guild_id", match.GuildID)
+		auditCtx := withSystemAuditSource(ctx, match.GuildID)
+
+		err = deleteGameGroupResources(discord, match.GuildID, match.RoleID, match.ChannelID)
+		if err == nil {
+			err = CloseLFGMatch(auditCtx, match.ID)
+		}
+		if err != nil {
+			logger.Error("Failed to close lfg match", "error", err)
+			continue
+		}
+		logger.Info("Lfg match closed")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLFGJoinOptionsWantedOpponents(t *testing.T) {
+	tests := []struct {
+		opponents string
+		want      []string
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"Luffy", []string{"Luffy"}},
+		{"Luffy, Nami ,Zoro", []string{"Luffy", "Nami", "Zoro"}},
+		{",Luffy,, ,Nami,", []string{"Luffy", "Nami"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.opponents, func(t *testing.T) {
+			options := &lfgJoinOptions{Opponents: tt.opponents}
+			if got := options.wantedOpponents(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wantedOpponents() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLFGEntryCompatible(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *LFGEntry
+		want bool
+	}{
+		{
+			name: "both want any opponent",
+			a:    &LFGEntry{Leader: "Luffy", Category: "Ranked"},
+			b:    &LFGEntry{Leader: "Nami", Category: "Ranked"},
+			want: true,
+		},
+		{
+			name: "different categories",
+			a:    &LFGEntry{Leader: "Luffy", Category: "Ranked"},
+			b:    &LFGEntry{Leader: "Nami", Category: "Casual"},
+			want: false,
+		},
+		{
+			name: "wanted leader matches ignoring case and spaces",
+			a:    &LFGEntry{Leader: "  Red Luffy ", Category: "Ranked"},
+			b:    &LFGEntry{Leader: "Nami", WantedOpponents: []string{"zoro", "red luffy"}, Category: "Ranked"},
+			want: true,
+		},
+		{
+			name: "one side doesn't want the other's leader",
+			a:    &LFGEntry{Leader: "Luffy", Category: "Ranked"},
+			b:    &LFGEntry{Leader: "Nami", WantedOpponents: []string{"zoro"}, Category: "Ranked"},
+			want: false,
+		},
+		{
+			name: "both want each other's leader",
+			a:    &LFGEntry{Leader: "Zoro", WantedOpponents: []string{"nami"}, Category: "Ranked"},
+			b:    &LFGEntry{Leader: "Nami", WantedOpponents: []string{"zoro"}, Category: "Ranked"},
+			want: true,
+		},
+		{
+			name: "only one side's wish is met",
+			a:    &LFGEntry{Leader: "Zoro", WantedOpponents: []string{"luffy"}, Category: "Ranked"},
+			b:    &LFGEntry{Leader: "Nami", WantedOpponents: []string{"zoro"}, Category: "Ranked"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Compatible(tt.b); got != tt.want {
+				t.Errorf("a.Compatible(b) = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Compatible(tt.a); got != tt.want {
+				t.Errorf("b.Compatible(a) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// newLogger builds the process logger. format is "text" for human readable lines or
+// "json" for one JSON object per line, which is what the log shipper expects.
+func newLogger(w io.Writer, format, level string) *slog.Logger {
+	var logLevel slog.Level
+	// The level is validated when the config loads, so the error can't happen here
+	_ = logLevel.UnmarshalText([]byte(level))
+
+	options := &slog.HandlerOptions{Level: logLevel}
+	if strings.EqualFold(format, "json") {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// interactionLogger returns a logger tagged with the interaction, command, guild and
+// user, so every line a handler writes can be traced back to the invocation
+func interactionLogger(i *discordgo.InteractionCreate) *slog.Logger {
+	return slog.With(
+		"interaction_id", i.ID,
+		"command", interactionName(i),
+		"guild_id", i.GuildID,
+		"user_id", interactionUser(i).ID,
+	)
+}
+
+// requestLogger returns a logger tagged with the HTTP method and path
+func requestLogger(r *http.Request) *slog.Logger {
+	return slog.With("method", r.Method, "path", r.URL.Path)
+}
+
+// interactionName is the command name or component prefix an interaction was routed by
+func interactionName(i *discordgo.InteractionCreate) string {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand, discordgo.InteractionApplicationCommandAutocomplete:
+		return i.ApplicationCommandData().Name
+	case discordgo.InteractionMessageComponent:
+		prefix, _, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+		return prefix
+	case discordgo.InteractionModalSubmit:
+		prefix, _, _ := strings.Cut(i.ModalSubmitData().CustomID, ":")
+		return prefix
+	}
+	return ""
+}
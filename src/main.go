@@ -1,73 +1,116 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 func main() {
+	// Load and validate configuration before touching anything else
+	config, err := LoadConfig(os.Args[1:])
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	AppConfig = config
+	slog.SetDefault(newLogger(os.Stderr, AppConfig.LogFormat, AppConfig.LogLevel))
+	AppConfig.LogSummary()
+
 	// Initialize database connection
-	err := InitDB()
+	err = InitDB()
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		slog.Error("Failed to initialize database", "error", err)
+		os.Exit(1)
 	}
-	defer CloseDB()
+
+	// Watch the connection in the background so outages show up in logs and metrics
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	go monitorDatabase(monitorCtx)
 
 	// Create tables if they don't exist
 	err = CreateTables()
 	if err != nil {
-		log.Fatalf("Failed to create tables: %v", err)
+		slog.Error("Failed to create tables", "error", err)
+		os.Exit(1)
 	}
 
-	discord, err := discordgo.New("Bot " + getEnv("DISCORD_TOKEN"))
+	discord, err := discordgo.New("Bot " + AppConfig.DiscordToken)
 	if err != nil {
-		err_msg := "Error creating Discord session: " + err.Error()
-		panic(err_msg)
+		slog.Error("Failed to create Discord session", "error", err)
+		os.Exit(1)
 	}
 
 	discord.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
-		log.Printf("Logged in as: %v#%v", s.State.User.Username, s.State.User.Discriminator)
+		slog.Info("Logged in", "username", s.State.User.Username, "discriminator", s.State.User.Discriminator)
 	})
 
 	discordAddHandlers(discord)
+	trackGatewayState(discord)
 
 	err = discord.Open()
 	if err != nil {
-		fmt.Println("Error opening Discord session: ", err)
+		slog.Error("Failed to open Discord session", "error", err)
+		os.Exit(1)
 	}
 
-	registeredCommands := make([]*discordgo.ApplicationCommand, len(commands))
-	for i, v := range commands {
-		fmt.Printf("Registering command '%v'...\n", v.Name)
-		cmd, err := discord.ApplicationCommandCreate(discord.State.User.ID, *GuildID, v)
-		if err != nil {
-			log.Panicf("Cannot create '%v' command: %v", v.Name, err)
-		}
-		registeredCommands[i] = cmd
+	// Only create, update or delete the commands that changed since the last run
+	registeredCommands, err := syncCommands(discord, AppConfig.GuildID, commands, AppConfig.CommandSyncDryRun)
+	if err != nil {
+		slog.Error("Failed to sync commands", "error", err)
+		os.Exit(1)
+	}
+	if AppConfig.CommandSyncDryRun {
+		discord.Close()
+		stopMonitor()
+		CloseDB()
+		return
 	}
 
-	// panic(1)
-	fmt.Println("Bot is now running. Press Ctrl+C to exit.")
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
-	<-stop
+	// Archive /create-game channels nobody has used for a while and remove old /lfg
+	// match channels
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	go archiveIdleGameGroups(jobsCtx, discord)
+	go closeStaleLFGMatches(jobsCtx, discord)
 
-	defer removeCommands(registeredCommands, discord)
+	// Start the HTTP API
+	httpServer := newHTTPServer(discord)
+	go func() {
+		slog.Info("HTTP server listening", "addr", httpServer.Addr)
+		err := httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server stopped", "error", err)
+		}
+	}()
 
-	defer discord.Close()
+	slog.Info("Bot is now running. Press Ctrl+C to exit.")
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	sig := <-stop
+	slog.Info("Received signal", "signal", sig.String())
 
+	stopMonitor()
+	stopJobs()
+	shutdown(discord, httpServer, registeredCommands)
 }
 
+// removeCommands deregisters the bot's commands. Failures are logged and skipped so
+// the rest of shutdown still runs.
 func removeCommands(registeredCommands []*discordgo.ApplicationCommand, discord *discordgo.Session) {
-	fmt.Println("Removing commands...")
+	slog.Info("Removing commands...")
 	for _, v := range registeredCommands {
-		err := discord.ApplicationCommandDelete(discord.State.User.ID, *GuildID, v.ID)
+		if v == nil {
+			continue
+		}
+		err := discord.ApplicationCommandDelete(discord.State.User.ID, AppConfig.GuildID, v.ID)
 		if err != nil {
-			log.Panicf("Cannot delete '%v' command: %v", v.Name, err)
+			slog.Error("Cannot delete command", "command", v.Name, "error", err)
 		}
 	}
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// metaDefaultDays is the /meta window when none is given
+	metaDefaultDays = 30
+	// metaMaxEntries is the number of opponent leaders listed by /meta
+	metaMaxEntries = 15
+	// metaTrendThreshold is the change in weekly play share, in percentage points,
+	// needed before a leader is shown as rising or falling
+	metaTrendThreshold = 2.0
+	// metaCoverageNote explains why older games are missing from the report: games
+	// recorded before the bot stored their server can't be attributed to one
+	metaCoverageNote = "ℹ️ Only games recorded in this server since the bot began tracking servers are counted."
+)
+
+// metaTrendArrow compares a leader's share of this week's games with its share of
+// last week's games
+func metaTrendArrow(report *MetaReport, entry *MetaEntry) string {
+	if report.ThisWeekGames == 0 || report.LastWeekGames == 0 {
+		return "➖"
+	}
+	if entry.LastWeek == 0 && entry.ThisWeek > 0 {
+		return "🆕"
+	}
+
+	thisWeekShare := 100 * float64(entry.ThisWeek) / float64(report.ThisWeekGames)
+	lastWeekShare := 100 * float64(entry.LastWeek) / float64(report.LastWeekGames)
+	switch {
+	case thisWeekShare-lastWeekShare >= metaTrendThreshold:
+		return "⬆️"
+	case lastWeekShare-thisWeekShare >= metaTrendThreshold:
+		return "⬇️"
+	default:
+		return "➡️"
+	}
+}
+
+type metaOptions struct {
+	Days     int    `option:"days" description:"How many days back to look (default 30)" min:"1" max:"365" default:"30"`
+	Category string `option:"category" description:"Only include games in this category" autocomplete:"category"`
+}
+
+var metaCommand = &slashCommand[metaOptions]{
+	Name:             "meta",
+	Description:      "Show which opponent leaders the server faces and how we do against them",
+	Contexts:         guildOnlyContexts,
+	IntegrationTypes: guildInstallTypes,
+	Run:              runMeta,
+}
+
+func runMeta(ctx context.Context, c *commandContext, options *metaOptions) error {
+	days := options.Days
+	category := NormalizeCategory(options.Category)
+
+	report, err := GetGuildMeta(ctx, c.GuildID(), days, category)
+	if err != nil {
+		return commandFailed("❌ Failed to load the meta report. Please try again later.", fmt.Errorf("failed to get guild meta: %w", err))
+	}
+
+	scope := "all categories"
+	if category != "" {
+		scope = category
+	}
+
+	if len(report.Entries) == 0 {
+		c.Replyf("📊 No games recorded in the last %d days (%s).\n%s", days, scope, metaCoverageNote)
+		return nil
+	}
+
+	var lines []string
+	for rank, entry := range report.Entries {
+		if rank == metaMaxEntries {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(report.Entries)-metaMaxEntries))
+			break
+		}
+		share := 100 * float64(entry.Games) / float64(report.TotalGames)
+		winRate := winRatePercent(entry.Wins, entry.Losses)
+		lines = append(lines, fmt.Sprintf("%d. %s **%s** — %.1f%% of games (%d) • %.0f%% win rate",
+			rank+1, metaTrendArrow(report, entry), entry.Leader, share, entry.Games, winRate))
+	}
+	c.Replyf("📊 **Server Meta — last %d days (%s)**\n🎮 %d games against %d leaders\n\n%s\n\n⬆️/⬇️ play share this week vs last week\n%s",
+		days, scope, report.TotalGames, len(report.Entries), strings.Join(lines, "\n"), metaCoverageNote)
+	return nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Metrics are exposed at /metrics in the Prometheus text format. The registry
+// below is intentionally small: counters and histograms with labels, plus
+// gauges read at scrape time.
+
+// defaultLatencyBuckets are histogram buckets in seconds, from 5ms to 30s
+var defaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+var (
+	commandsTotal = newCounterVec("bot_commands_total",
+		"Interactions handled, by command and outcome.", "command", "outcome")
+	commandDuration = newHistogramVec("bot_command_duration_seconds",
+		"Time spent in interaction handlers.", defaultLatencyBuckets, "command")
+	dbQueryDuration = newHistogramVec("bot_db_query_duration_seconds",
+		"Time spent on database round trips, by operation.", defaultLatencyBuckets, "operation")
+	gamesRecordedTotal = newCounterVec("bot_games_recorded_total",
+		"Games recorded, by category. Servers' own categories are counted as custom.", "category")
+	dbConnectionEventsTotal = newCounterVec("bot_db_connection_events_total",
+		"Database connectivity changes seen by the background health check, by event (lost or restored).", "event")
+
+	processStartTime = time.Now()
+)
+
+// categoryMetricLabel keeps the category label bounded: the default categories are
+// reported by name and every server-defined category as "custom"
+func categoryMetricLabel(category string) string {
+	for _, name := range GameCategories {
+		if category == name {
+			return name
+		}
+	}
+	return "custom"
+}
+
+// metricsCollectors are written out in this order on every scrape
+var metricsCollectors = []interface{ writeTo(w io.Writer) }{
+	commandsTotal,
+	commandDuration,
+	dbQueryDuration,
+	gamesRecordedTotal,
+	dbConnectionEventsTotal,
+	gaugeFunc("bot_db_up", "Whether the last background database health check succeeded.", func() float64 {
+		if dbHealthy.Load() {
+			return 1
+		}
+		return 0
+	}),
+	gaugeFunc("bot_db_pool_open_connections", "Open database connections.", func() float64 {
+		return float64(DB.Stats().OpenConnections)
+	}),
+	gaugeFunc("bot_db_pool_in_use_connections", "Database connections currently in use.", func() float64 {
+		return float64(DB.Stats().InUse)
+	}),
+	gaugeFunc("bot_db_pool_idle_connections", "Idle database connections.", func() float64 {
+		return float64(DB.Stats().Idle)
+	}),
+	gaugeFunc("bot_db_pool_max_open_connections", "Maximum open database connections (0 is unlimited).", func() float64 {
+		return float64(DB.Stats().MaxOpenConnections)
+	}),
+	counterFunc("bot_db_pool_wait_count_total", "Connections waited for.", func() float64 {
+		return float64(DB.Stats().WaitCount)
+	}),
+	counterFunc("bot_db_pool_wait_duration_seconds_total", "Time spent waiting for connections.", func() float64 {
+		return DB.Stats().WaitDuration.Seconds()
+	}),
+	gaugeFunc("process_start_time_seconds", "Start time of the process since the Unix epoch.", func() float64 {
+		return float64(processStartTime.Unix())
+	}),
+}
+
+// metricsHandler serves all metrics in the Prometheus text format
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	for _, collector := range metricsCollectors {
+		collector.writeTo(w)
+	}
+}
+
+// counterVec is a counter partitioned by label values
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: map[string]float64{}}
+}
+
+// Inc adds one to the counter with the given label values
+func (c *counterVec) Inc(labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[formatLabels(c.labels, labelValues)]++
+}
+
+func (c *counterVec) writeTo(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, labels := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labels, formatMetricValue(c.values[labels]))
+	}
+}
+
+// histogramVec is a histogram partitioned by label values
+type histogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogramSeries{}}
+}
+
+// Observe records a value for the given label values
+func (h *histogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := formatLabels(h.labels, labelValues)
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+
+	for idx, bound := range h.buckets {
+		if value <= bound {
+			series.counts[idx]++
+		}
+	}
+	series.sum += value
+	series.count++
+}
+
+// ObserveSince records the time elapsed since start
+func (h *histogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *histogramVec) writeTo(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.series) {
+		series := h.series[key]
+		bucketLabels := append(append([]string{}, h.labels...), "le")
+		for idx, bound := range h.buckets {
+			labels := formatLabels(bucketLabels, append(append([]string{}, series.labelValues...), formatMetricValue(bound)))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, series.counts[idx])
+		}
+		labels := formatLabels(bucketLabels, append(append([]string{}, series.labelValues...), "+Inf"))
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, key, formatMetricValue(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, key, series.count)
+	}
+}
+
+// funcMetric is a single unlabelled value read at scrape time
+type funcMetric struct {
+	name       string
+	help       string
+	metricType string
+	value      func() float64
+}
+
+func gaugeFunc(name, help string, value func() float64) *funcMetric {
+	return &funcMetric{name: name, help: help, metricType: "gauge", value: value}
+}
+
+func counterFunc(name, help string, value func() float64) *funcMetric {
+	return &funcMetric{name: name, help: help, metricType: "counter", value: value}
+}
+
+func (m *funcMetric) writeTo(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", m.name, m.help, m.name, m.metricType, m.name, formatMetricValue(m.value()))
+}
+
+// formatLabels renders label pairs as {a="1",b="2"}, escaping values
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, len(names))
+	for idx, name := range names {
+		value := ""
+		if idx < len(values) {
+			value = values[idx]
+		}
+		value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+		pairs[idx] = fmt.Sprintf(`%s="%s"`, name, value)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatMetricValue renders a sample value the way Prometheus expects
+func formatMetricValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return fmt.Sprintf("%g", value)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Interaction outcomes are tracked per interaction ID while its handler runs, so
+// the error helpers can flag a failure without every handler returning one
+var (
+	interactionOutcomesMu sync.Mutex
+	interactionOutcomes   = map[string]string{}
+)
+
+// markInteractionOutcome records that an interaction ended in an error ("error") or
+// ran out of time ("timeout") instead of succeeding
+func markInteractionOutcome(i *discordgo.InteractionCreate, outcome string) {
+	interactionOutcomesMu.Lock()
+	defer interactionOutcomesMu.Unlock()
+	interactionOutcomes[i.ID] = outcome
+}
+
+// observeInteraction runs a handler and records its latency and outcome
+func observeInteraction(name string, i *discordgo.InteractionCreate, handler func()) {
+	start := time.Now()
+	handler()
+	commandDuration.ObserveSince(start, name)
+
+	interactionOutcomesMu.Lock()
+	outcome, failed := interactionOutcomes[i.ID]
+	delete(interactionOutcomes, i.ID)
+	interactionOutcomesMu.Unlock()
+
+	if !failed {
+		outcome = "success"
+	}
+	commandsTotal.Inc(name, outcome)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCounterVecWritesPrometheusText(t *testing.T) {
+	counter := newCounterVec("test_total", "Things counted.", "kind", "outcome")
+	counter.Inc("b", "ok")
+	counter.Inc("a", "ok")
+	counter.Inc("a", "ok")
+	counter.Inc("a", `say "hi"`)
+
+	var out strings.Builder
+	counter.writeTo(&out)
+
+	want := `# HELP test_total Things counted.
+# TYPE test_total counter
+test_total{kind="a",outcome="ok"} 2
+test_total{kind="a",outcome="say \"hi\""} 1
+test_total{kind="b",outcome="ok"} 1
+`
+	if out.String() != want {
+		t.Errorf("writeTo() wrote\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestHistogramVecWritesPrometheusText(t *testing.T) {
+	histogram := newHistogramVec("test_seconds", "Time taken.", []float64{0.1, 1}, "command")
+	histogram.Observe(0.05, "ping")
+	histogram.Observe(0.5, "ping")
+	histogram.Observe(3, "ping")
+
+	var out strings.Builder
+	histogram.writeTo(&out)
+
+	want := `# HELP test_seconds Time taken.
+# TYPE test_seconds histogram
+test_seconds_bucket{command="ping",le="0.1"} 1
+test_seconds_bucket{command="ping",le="1"} 2
+test_seconds_bucket{command="ping",le="+Inf"} 3
+test_seconds_sum{command="ping"} 3.55
+test_seconds_count{command="ping"} 3
+`
+	if out.String() != want {
+		t.Errorf("writeTo() wrote\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestFuncMetricWritesPrometheusText(t *testing.T) {
+	var out strings.Builder
+	gaugeFunc("test_up", "Whether it's up.", func() float64 { return 1 }).writeTo(&out)
+	counterFunc("test_waits_total", "Waits.", func() float64 { return 12 }).writeTo(&out)
+
+	want := `# HELP test_up Whether it's up.
+# TYPE test_up gauge
+test_up 1
+# HELP test_waits_total Waits.
+# TYPE test_waits_total counter
+test_waits_total 12
+`
+	if out.String() != want {
+		t.Errorf("writeTo() wrote\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // User represents a Discord user in our system
@@ -19,7 +28,13 @@ type User struct {
 }
 
 // CreateUser inserts a new user into the database
-func CreateUser(discordID, username, discriminator string) (*User, error) {
+func CreateUser(ctx context.Context, discordID, username, discriminator string) (*User, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin user transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO users (discord_id, username, discriminator)
 		VALUES ($1, $2, $3)
@@ -27,7 +42,7 @@ func CreateUser(discordID, username, discriminator string) (*User, error) {
 	`
 
 	user := &User{}
-	err := DB.QueryRow(query, discordID, username, discriminator).Scan(
+	err = tx.QueryRowContext(ctx, query, discordID, username, discriminator).Scan(
 		&user.ID,
 		&user.DiscordID,
 		&user.Username,
@@ -41,11 +56,24 @@ func CreateUser(discordID, username, discriminator string) (*User, error) {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	err = recordAudit(ctx, tx, AuditActionUserCreated, "user", user.ID, nil, user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit user: %w", err)
+	}
+
 	return user, nil
 }
 
+// ErrUserNotFound is returned when a user doesn't exist
+var ErrUserNotFound = errors.New("user not found")
+
 // GetUserByDiscordID retrieves a user by their Discord ID
-func GetUserByDiscordID(discordID string) (*User, error) {
+func GetUserByDiscordID(ctx context.Context, discordID string) (*User, error) {
 	query := `
 		SELECT id, discord_id, username, discriminator, timezone, created_at, updated_at
 		FROM users
@@ -53,7 +81,36 @@ func GetUserByDiscordID(discordID string) (*User, error) {
 	`
 
 	user := &User{}
-	err := DB.QueryRow(query, discordID).Scan(
+	err := DB.QueryRowContext(ctx, query, discordID).Scan(
+		&user.ID,
+		&user.DiscordID,
+		&user.Username,
+		&user.Discriminator,
+		&user.Timezone,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserByID retrieves a user by their internal ID
+func GetUserByID(ctx context.Context, id int) (*User, error) {
+	query := `
+		SELECT id, discord_id, username, discriminator, timezone, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+
+	user := &User{}
+	err := DB.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.DiscordID,
 		&user.Username,
@@ -65,7 +122,7 @@ func GetUserByDiscordID(discordID string) (*User, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -74,125 +131,2908 @@ func GetUserByDiscordID(discordID string) (*User, error) {
 }
 
 // UpdateUserTimezone updates a user's timezone
-func UpdateUserTimezone(discordID, timezone string) error {
+func UpdateUserTimezone(ctx context.Context, discordID, timezone string) error {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin timezone transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		UPDATE users 
+		UPDATE users u
 		SET timezone = $1, updated_at = NOW()
-		WHERE discord_id = $2
+		FROM (SELECT id, timezone FROM users WHERE discord_id = $2 FOR UPDATE) old
+		WHERE u.id = old.id
+		RETURNING u.id, old.timezone
 	`
 
-	result, err := DB.Exec(query, timezone, discordID)
+	var userID int
+	var previous string
+	err = tx.QueryRowContext(ctx, query, timezone, discordID).Scan(&userID, &previous)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
 		return fmt.Errorf("failed to update user timezone: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	err = recordAudit(ctx, tx, AuditActionTimezoneUpdated, "user", userID,
+		map[string]string{"timezone": previous}, map[string]string{"timezone": timezone})
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit user timezone: %w", err)
 	}
 
 	return nil
 }
 
 // GetOrCreateUser gets an existing user or creates a new one
-func GetOrCreateUser(discordID, username, discriminator string) (*User, error) {
+func GetOrCreateUser(ctx context.Context, discordID, username, discriminator string) (*User, error) {
 	// Try to get existing user first
-	user, err := GetUserByDiscordID(discordID)
+	user, err := GetUserByDiscordID(ctx, discordID)
 	if err == nil {
 		return user, nil
 	}
 
 	// If user doesn't exist, create a new one
-	if err.Error() == "user not found" {
-		return CreateUser(discordID, username, discriminator)
+	if errors.Is(err, ErrUserNotFound) {
+		return CreateUser(ctx, discordID, username, discriminator)
 	}
 
 	// Some other error occurred
 	return nil, fmt.Errorf("failed to get or create user: %w", err)
 }
 
+// Game result statuses. Games against an opponent who isn't a server member are
+// "recorded" straight away; games against another member start out "pending" until
+// the opponent player confirms or disputes them.
+const (
+	GameStatusRecorded  = "recorded"
+	GameStatusPending   = "pending"
+	GameStatusConfirmed = "confirmed"
+	GameStatusDisputed  = "disputed"
+)
+
 // GameResult represents a game result record
 type GameResult struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	Leader    string    `json:"leader"`
-	Opponent  string    `json:"opponent"`
-	Category  string    `json:"category"`
-	WentFirst bool      `json:"went_first"`
-	Won       bool      `json:"won"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             int       `json:"id"`
+	UserID         int       `json:"user_id"`
+	OpponentUserID *int      `json:"opponent_user_id,omitempty"`
+	GuildID        string    `json:"guild_id,omitempty"`
+	Leader         string    `json:"leader"`
+	Opponent       string    `json:"opponent"`
+	Category       string    `json:"category"`
+	WentFirst      bool      `json:"went_first"`
+	Outcome        Outcome   `json:"outcome"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+	// SessionID is the reporter's practice session the game was recorded in, if any
+	SessionID *int `json:"session_id,omitempty"`
+	GameDetails
 }
 
-// CreateGameResult inserts a new game result into the database
-func CreateGameResult(userID int, leader, opponent, category string, wentFirst, won bool) (*GameResult, error) {
-	query := `
-		INSERT INTO game_results (user_id, leader, opponent, category, went_first, won)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, user_id, leader, opponent, category, went_first, won, created_at
-	`
+// GameDetails are the optional details recorded with a game. Nil fields weren't
+// recorded.
+type GameDetails struct {
+	// Turns is how many turns the game lasted
+	Turns *int `json:"turns,omitempty"`
+	// LifeRemaining and OpponentLifeRemaining are each side's life cards at game end
+	LifeRemaining         *int `json:"life_remaining,omitempty"`
+	OpponentLifeRemaining *int `json:"opponent_life_remaining,omitempty"`
+	DurationMinutes       *int `json:"duration_minutes,omitempty"`
+	// Mulliganed is whether the player mulliganed their opening hand, and KeyCards how
+	// many of their key cards they opened with after keeping or mulliganing
+	Mulliganed *bool `json:"mulliganed,omitempty"`
+	KeyCards   *int  `json:"key_cards,omitempty"`
+}
+
+// Summary describes the recorded details in one line, or "" if there are none
+func (d GameDetails) Summary() string {
+	var parts []string
+	if d.Turns != nil {
+		parts = append(parts, fmt.Sprintf("%d turns", *d.Turns))
+	}
+	if d.LifeRemaining != nil || d.OpponentLifeRemaining != nil {
+		parts = append(parts, fmt.Sprintf("life %s–%s", formatOptionalInt(d.LifeRemaining), formatOptionalInt(d.OpponentLifeRemaining)))
+	}
+	if d.DurationMinutes != nil {
+		parts = append(parts, fmt.Sprintf("%d min", *d.DurationMinutes))
+	}
+	if d.Mulliganed != nil {
+		if *d.Mulliganed {
+			parts = append(parts, "mulliganed")
+		} else {
+			parts = append(parts, "kept")
+		}
+	}
+	if d.KeyCards != nil {
+		parts = append(parts, fmt.Sprintf("%d key cards", *d.KeyCards))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// formatOptionalInt formats a detail that may not have been recorded
+func formatOptionalInt(n *int) string {
+	if n == nil {
+		return "?"
+	}
+	return strconv.Itoa(*n)
+}
+
+// gameResultColumns lists the game_results columns in the order scanGameResult expects them
+const gameResultColumns = `id, user_id, opponent_user_id, COALESCE(guild_id, ''), leader, opponent, category, went_first, outcome, status, created_at,
+	turns, life_remaining, opponent_life_remaining, duration_minutes, mulliganed, key_cards, session_id`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
+// scanGameResult scans a row selected with gameResultColumns
+func scanGameResult(row rowScanner) (*GameResult, error) {
 	gameResult := &GameResult{}
-	err := DB.QueryRow(query, userID, leader, opponent, category, wentFirst, won).Scan(
+	var opponentUserID, sessionID sql.NullInt64
+	err := row.Scan(
 		&gameResult.ID,
 		&gameResult.UserID,
+		&opponentUserID,
+		&gameResult.GuildID,
 		&gameResult.Leader,
 		&gameResult.Opponent,
 		&gameResult.Category,
 		&gameResult.WentFirst,
-		&gameResult.Won,
+		&gameResult.Outcome,
+		&gameResult.Status,
 		&gameResult.CreatedAt,
+		&gameResult.Turns,
+		&gameResult.LifeRemaining,
+		&gameResult.OpponentLifeRemaining,
+		&gameResult.DurationMinutes,
+		&gameResult.Mulliganed,
+		&gameResult.KeyCards,
+		&sessionID,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if opponentUserID.Valid {
+		id := int(opponentUserID.Int64)
+		gameResult.OpponentUserID = &id
+	}
+	if sessionID.Valid {
+		id := int(sessionID.Int64)
+		gameResult.SessionID = &id
+	}
+
+	return gameResult, nil
+}
+
+// CreateGameResult inserts a new game result into the database. guildID is the guild
+// the game was recorded in, or empty when it was recorded outside a guild.
+func CreateGameResult(ctx context.Context, userID int, guildID, leader, opponent, category string, wentFirst bool, outcome Outcome, details GameDetails) (*GameResult, error) {
+	if !outcome.Valid() {
+		return nil, fmt.Errorf("invalid outcome: %s", outcome)
+	}
+
+	query := `
+		INSERT INTO game_results (user_id, guild_id, leader, opponent, category, went_first, outcome,
+			turns, life_remaining, opponent_life_remaining, duration_minutes, mulliganed, key_cards, session_id)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, ` + openSessionIDQuery + `)
+		RETURNING ` + gameResultColumns
+
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin game transaction: %w", err)
+	}
+	defer tx.Rollback()
 
+	gameResult, err := scanGameResult(tx.QueryRowContext(ctx, query, userID, guildID, leader, opponent, category, wentFirst, outcome,
+		details.Turns, details.LifeRemaining, details.OpponentLifeRemaining, details.DurationMinutes,
+		details.Mulliganed, details.KeyCards))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create game result: %w", err)
 	}
 
+	err = recordAudit(ctx, tx, AuditActionGameCreated, "game", gameResult.ID, nil, gameResult)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit game result: %w", err)
+	}
+
+	gamesRecordedTotal.Inc(categoryMetricLabel(gameResult.Category))
+
 	return gameResult, nil
 }
 
-// ValidateCategory checks if the provided category is valid
-func ValidateCategory(category string) bool {
-	validCategories := []string{
-		"Casual",
-		"Ranked",
-		"Locals",
-		"Regional",
-		"National",
-		"Tournament",
-		"Practice",
-		"Online",
-	}
-
-	for _, valid := range validCategories {
-		if strings.EqualFold(category, valid) {
-			return true
+// CreatePlayerGameResult inserts a game played against another server member. The game
+// stays pending until the opponent player confirms or disputes it.
+func CreatePlayerGameResult(ctx context.Context, userID, opponentUserID int, guildID, leader, opponent, category string, wentFirst bool, outcome Outcome, details GameDetails) (*GameResult, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin game transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	gameResult, err := insertPlayerGameResult(ctx, tx, userID, opponentUserID, guildID, leader, opponent, category, wentFirst, outcome, details)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit player game result: %w", err)
+	}
+
+	gamesRecordedTotal.Inc(categoryMetricLabel(gameResult.Category))
+
+	return gameResult, nil
+}
+
+// insertPlayerGameResult inserts a pending player game and its audit entry in tx
+func insertPlayerGameResult(ctx context.Context, tx *sql.Tx, userID, opponentUserID int, guildID, leader, opponent, category string, wentFirst bool, outcome Outcome, details GameDetails) (*GameResult, error) {
+	if !outcome.Valid() {
+		return nil, fmt.Errorf("invalid outcome: %s", outcome)
+	}
+
+	query := `
+		INSERT INTO game_results (user_id, opponent_user_id, guild_id, leader, opponent, category, went_first, outcome, status,
+			turns, life_remaining, opponent_life_remaining, duration_minutes, mulliganed, key_cards, session_id)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, ` + openSessionIDQuery + `)
+		RETURNING ` + gameResultColumns
+
+	gameResult, err := scanGameResult(tx.QueryRowContext(ctx, query, userID, opponentUserID, guildID, leader, opponent, category, wentFirst, outcome, GameStatusPending,
+		details.Turns, details.LifeRemaining, details.OpponentLifeRemaining, details.DurationMinutes,
+		details.Mulliganed, details.KeyCards))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create player game result: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionGameCreated, "game", gameResult.ID, nil, gameResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return gameResult, nil
+}
+
+// Errors returned by the game result functions
+var (
+	ErrGameResultNotFound  = errors.New("game result not found")
+	ErrPendingGameNotFound = errors.New("pending game not found")
+)
+
+// GetGameResultByID retrieves a game result by its ID
+func GetGameResultByID(ctx context.Context, id int) (*GameResult, error) {
+	query := `SELECT ` + gameResultColumns + ` FROM game_results WHERE id = $1`
+
+	gameResult, err := scanGameResult(DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGameResultNotFound
 		}
+		return nil, fmt.Errorf("failed to get game result: %w", err)
 	}
 
-	return false
+	return gameResult, nil
 }
 
-// NormalizeCategory normalizes the category to proper capitalization
-func NormalizeCategory(category string) string {
-	validCategories := map[string]string{
-		"casual":     "Casual",
-		"ranked":     "Ranked",
-		"locals":     "Locals",
-		"regional":   "Regional",
-		"national":   "National",
-		"tournament": "Tournament",
-		"practice":   "Practice",
-		"online":     "Online",
+// ResolvePlayerGameResult moves a pending player game to the given status. Only the
+// opponent player may resolve it, and only while it is still pending.
+func ResolvePlayerGameResult(ctx context.Context, gameID, opponentUserID int, status string) (*GameResult, error) {
+	if status != GameStatusConfirmed && status != GameStatusDisputed {
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+
+	query := `
+		UPDATE game_results
+		SET status = $1
+		WHERE id = $2 AND opponent_user_id = $3 AND status = $4
+		RETURNING ` + gameResultColumns
+
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin game transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	gameResult, err := scanGameResult(tx.QueryRowContext(ctx, query, status, gameID, opponentUserID, GameStatusPending))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPendingGameNotFound
+		}
+		return nil, fmt.Errorf("failed to resolve player game result: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionGameResolved, "game", gameResult.ID,
+		map[string]string{"status": GameStatusPending}, map[string]string{"status": status})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit player game result: %w", err)
+	}
+
+	return gameResult, nil
+}
+
+// playerGameColumns selects player_games rows in the order scanGameResult expects them
+const playerGameColumns = `game_id, user_id, opponent_user_id, COALESCE(guild_id, ''), leader, opponent, category, went_first, outcome, status, created_at,
+	turns, life_remaining, opponent_life_remaining, duration_minutes, mulliganed, key_cards, session_id`
+
+// GetUserGameResults returns a page of a user's games, newest first, from the user's
+// own perspective. Confirmed games recorded by an opponent player are included.
+func GetUserGameResults(ctx context.Context, userID int, filter StatsFilter, limit, offset int) ([]*GameResult, error) {
+	query := `
+		SELECT ` + playerGameColumns + `
+		FROM player_games
+		WHERE user_id = $1 AND ` + statsFilterClause + `
+		ORDER BY created_at DESC, game_id DESC
+		LIMIT $5 OFFSET $6
+	`
+
+	args := append([]any{userID}, filter.args()...)
+	rows, err := DB.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user game results: %w", err)
+	}
+	defer rows.Close()
+
+	gameResults := []*GameResult{}
+	for rows.Next() {
+		gameResult, err := scanGameResult(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan game result: %w", err)
+		}
+		gameResults = append(gameResults, gameResult)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user game results: %w", err)
+	}
+
+	return gameResults, nil
+}
+
+// HeadToHeadRecord summarizes the confirmed games between two server members
+type HeadToHeadRecord struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+	// Matchups holds the record per leader pairing, from the first user's perspective
+	Matchups []HeadToHeadMatchup `json:"matchups"`
+}
+
+// HeadToHeadMatchup is the record for a single leader pairing in a head-to-head
+type HeadToHeadMatchup struct {
+	Leader   string `json:"leader"`
+	Opponent string `json:"opponent"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Draws    int    `json:"draws"`
+}
+
+// GetHeadToHead returns the verified record of userID against opponentUserID.
+// Only confirmed games count.
+func GetHeadToHead(ctx context.Context, userID, opponentUserID int) (*HeadToHeadRecord, error) {
+	query := `
+		SELECT leader, opponent,
+			COUNT(*) FILTER (WHERE ` + winOutcomeFilter + `) AS wins,
+			COUNT(*) FILTER (WHERE ` + lossOutcomeFilter + `) AS losses,
+			COUNT(*) FILTER (WHERE ` + drawOutcomeFilter + `) AS draws
+		FROM player_games
+		WHERE user_id = $1 AND opponent_user_id = $2 AND status = $3
+		GROUP BY leader, opponent
+		ORDER BY COUNT(*) DESC, leader, opponent
+	`
+
+	rows, err := DB.QueryContext(ctx, query, userID, opponentUserID, GameStatusConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head-to-head: %w", err)
+	}
+	defer rows.Close()
+
+	record := &HeadToHeadRecord{}
+	for rows.Next() {
+		var matchup HeadToHeadMatchup
+		err = rows.Scan(&matchup.Leader, &matchup.Opponent, &matchup.Wins, &matchup.Losses, &matchup.Draws)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan head-to-head: %w", err)
+		}
+		record.Wins += matchup.Wins
+		record.Losses += matchup.Losses
+		record.Draws += matchup.Draws
+		record.Matchups = append(record.Matchups, matchup)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get head-to-head: %w", err)
+	}
+
+	return record, nil
+}
+
+// PlayerRating is a user's Glicko-2 rating in a single category of one server. Ratings
+// only come from games against members of the same server, so each server has its own.
+type PlayerRating struct {
+	GuildID   string    `json:"guild_id"`
+	UserID    int       `json:"user_id"`
+	DiscordID string    `json:"discord_id"`
+	Username  string    `json:"username"`
+	Category  string    `json:"category"`
+	Games     int       `json:"games"`
+	Wins      int       `json:"wins"`
+	Losses    int       `json:"losses"`
+	UpdatedAt time.Time `json:"updated_at"`
+	GlickoRating
+}
+
+// Draws is the number of rated draws, which are the games that were neither won nor lost
+func (r *PlayerRating) Draws() int {
+	return r.Games - r.Wins - r.Losses
+}
+
+// playerRatingColumns lists the ratings columns in the order scanPlayerRating expects them
+const playerRatingColumns = `r.guild_id, r.user_id, u.discord_id, u.username, r.category, r.rating, r.rd, r.volatility, r.games, r.wins, r.losses, r.updated_at`
+
+// scanPlayerRating scans a row selected with playerRatingColumns
+func scanPlayerRating(row rowScanner) (*PlayerRating, error) {
+	rating := &PlayerRating{}
+	err := row.Scan(
+		&rating.GuildID,
+		&rating.UserID,
+		&rating.DiscordID,
+		&rating.Username,
+		&rating.Category,
+		&rating.Rating,
+		&rating.RD,
+		&rating.Volatility,
+		&rating.Games,
+		&rating.Wins,
+		&rating.Losses,
+		&rating.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return rating, nil
+}
+
+// GetUserRatings returns a user's category ratings in one server, highest first. An
+// empty guildID returns their ratings in every server.
+func GetUserRatings(ctx context.Context, userID int, guildID string) ([]*PlayerRating, error) {
+	query := `
+		SELECT ` + playerRatingColumns + `
+		FROM ratings r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.user_id = $1 AND ($2 = '' OR r.guild_id = $2)
+		ORDER BY r.rating DESC
+	`
+
+	rows, err := DB.QueryContext(ctx, query, userID, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var ratings []*PlayerRating
+	for rows.Next() {
+		rating, err := scanPlayerRating(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		ratings = append(ratings, rating)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user ratings: %w", err)
+	}
+
+	return ratings, nil
+}
+
+// GetRatingLeaderboard returns the highest rated members of a server in a category
+func GetRatingLeaderboard(ctx context.Context, guildID, category string, limit int) ([]*PlayerRating, error) {
+	query := `
+		SELECT ` + playerRatingColumns + `
+		FROM ratings r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.guild_id = $1 AND r.category = $2
+		ORDER BY r.rating DESC, r.rd ASC
+		LIMIT $3
+	`
+
+	rows, err := DB.QueryContext(ctx, query, guildID, category, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var ratings []*PlayerRating
+	for rows.Next() {
+		rating, err := scanPlayerRating(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		ratings = append(ratings, rating)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get rating leaderboard: %w", err)
+	}
+
+	return ratings, nil
+}
+
+// ratingState is a rating being updated in memory
+type ratingState struct {
+	GlickoRating
+	games  int
+	wins   int
+	losses int
+}
+
+// applyRatedGame updates both players' ratings for a single game, scored from the
+// player's side. Each game is treated as its own rating period, so ratings move
+// immediately after every result.
+func applyRatedGame(player, opponent *ratingState, score float64) {
+	playerBefore := player.GlickoRating
+	player.GlickoRating = player.Update([]GlickoResult{{Opponent: opponent.GlickoRating, Score: score}})
+	opponent.GlickoRating = opponent.Update([]GlickoResult{{Opponent: playerBefore, Score: 1 - score}})
+
+	player.games++
+	opponent.games++
+	switch score {
+	case 1:
+		player.wins++
+		opponent.losses++
+	case 0:
+		player.losses++
+		opponent.wins++
+	}
+}
+
+// UpdateRatingsForGame applies a confirmed player game to both players' ratings in its
+// server and category. Outcomes that aren't rated leave the ratings alone.
+func UpdateRatingsForGame(ctx context.Context, gameResult *GameResult) error {
+	if gameResult.OpponentUserID == nil || gameResult.Status != GameStatusConfirmed || gameResult.GuildID == "" {
+		return fmt.Errorf("game %d is not a confirmed player game", gameResult.ID)
+	}
+	score, rated := gameResult.Outcome.ratingScore()
+	if !rated {
+		return nil
+	}
+
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin rating transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	defaultRating := NewGlickoRating()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO ratings (guild_id, user_id, category, rating, rd, volatility)
+		VALUES ($7, $1, $3, $4, $5, $6), ($7, $2, $3, $4, $5, $6)
+		ON CONFLICT (guild_id, user_id, category) DO NOTHING
+	`, gameResult.UserID, *gameResult.OpponentUserID, gameResult.Category,
+		defaultRating.Rating, defaultRating.RD, defaultRating.Volatility, gameResult.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to initialize ratings: %w", err)
+	}
+
+	// Lock both rows in a consistent order so concurrent confirmations can't deadlock
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, rating, rd, volatility, games, wins, losses
+		FROM ratings
+		WHERE guild_id = $4 AND category = $1 AND user_id IN ($2, $3)
+		ORDER BY user_id
+		FOR UPDATE
+	`, gameResult.Category, gameResult.UserID, *gameResult.OpponentUserID, gameResult.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to lock ratings: %w", err)
+	}
+
+	states := map[int]*ratingState{}
+	for rows.Next() {
+		var userID int
+		state := &ratingState{}
+		err = rows.Scan(&userID, &state.Rating, &state.RD, &state.Volatility, &state.games, &state.wins, &state.losses)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan rating: %w", err)
+		}
+		states[userID] = state
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to lock ratings: %w", err)
+	}
+
+	player, opponent := states[gameResult.UserID], states[*gameResult.OpponentUserID]
+	if player == nil || opponent == nil {
+		return fmt.Errorf("ratings missing for game %d", gameResult.ID)
+	}
+
+	applyRatedGame(player, opponent, score)
+
+	for userID, state := range map[int]*ratingState{gameResult.UserID: player, *gameResult.OpponentUserID: opponent} {
+		err = saveRatingState(ctx, tx, gameResult.GuildID, userID, gameResult.Category, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit ratings: %w", err)
+	}
+
+	return nil
+}
+
+// RecomputeRatings rebuilds a server's ratings by replaying its rated confirmed player
+// games in the order they were played. Other servers' ratings are left alone. It
+// returns the number of games replayed.
+func RecomputeRatings(ctx context.Context, guildID string) (int, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin rating transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Block concurrent confirmations until the rebuild is committed
+	_, err = tx.ExecContext(ctx, `LOCK TABLE ratings IN EXCLUSIVE MODE`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock ratings table: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM ratings WHERE guild_id = $1`, guildID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear ratings: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, opponent_user_id, category, outcome
+		FROM game_results
+		WHERE opponent_user_id IS NOT NULL AND status = $1 AND guild_id = $2
+		ORDER BY created_at, id
+	`, GameStatusConfirmed, guildID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get confirmed games: %w", err)
+	}
+
+	type ratingKey struct {
+		userID   int
+		category string
+	}
+	states := map[ratingKey]*ratingState{}
+	stateFor := func(key ratingKey) *ratingState {
+		state, ok := states[key]
+		if !ok {
+			state = &ratingState{GlickoRating: NewGlickoRating()}
+			states[key] = state
+		}
+		return state
+	}
+
+	gamesReplayed := 0
+	for rows.Next() {
+		var userID, opponentUserID int
+		var category string
+		var outcome Outcome
+		err = rows.Scan(&userID, &opponentUserID, &category, &outcome)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan confirmed game: %w", err)
+		}
+
+		score, rated := outcome.ratingScore()
+		if !rated {
+			continue
+		}
+		applyRatedGame(stateFor(ratingKey{userID, category}), stateFor(ratingKey{opponentUserID, category}), score)
+		gamesReplayed++
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to get confirmed games: %w", err)
+	}
+
+	for key, state := range states {
+		err = saveRatingState(ctx, tx, guildID, key.userID, key.category, state)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = recordAudit(ctx, tx, AuditActionRatingsRecomputed, "", 0, nil,
+		map[string]int{"games_replayed": gamesReplayed, "ratings": len(states)})
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("failed to commit ratings: %w", err)
+	}
+
+	return gamesReplayed, nil
+}
+
+// saveRatingState upserts a rating within a transaction
+func saveRatingState(ctx context.Context, tx *sql.Tx, guildID string, userID int, category string, state *ratingState) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO ratings (guild_id, user_id, category, rating, rd, volatility, games, wins, losses, updated_at)
+		VALUES ($9, $1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		ON CONFLICT (guild_id, user_id, category) DO UPDATE
+		SET rating = EXCLUDED.rating, rd = EXCLUDED.rd, volatility = EXCLUDED.volatility,
+			games = EXCLUDED.games, wins = EXCLUDED.wins, losses = EXCLUDED.losses, updated_at = NOW()
+	`, userID, category, state.Rating, state.RD, state.Volatility, state.games, state.wins, state.losses, guildID)
+	if err != nil {
+		return fmt.Errorf("failed to save rating: %w", err)
+	}
+
+	return nil
+}
+
+// hashToken returns the hex SHA-256 of a secret token, which is what gets stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateToken returns a random hex token with the given number of bytes of entropy
+func generateToken(size int) (string, error) {
+	buf := make([]byte, size)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// CreateAPIToken issues a new API token for a user, replacing any previous token.
+// The plain token is only ever returned here.
+func CreateAPIToken(ctx context.Context, userID int) (string, error) {
+	token, err := generateToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin api token transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO api_tokens (user_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, created_at = NOW(), last_used_at = NULL
+	`
+
+	_, err = tx.ExecContext(ctx, query, userID, hashToken(token))
+	if err != nil {
+		return "", fmt.Errorf("failed to create api token: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionAPITokenCreated, "user", userID, nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", fmt.Errorf("failed to commit api token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RevokeAPIToken deletes a user's API token, reporting whether one existed
+func RevokeAPIToken(ctx context.Context, userID int) (bool, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin api token transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM api_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke api token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	err = recordAudit(ctx, tx, AuditActionAPITokenRevoked, "user", userID, nil, nil)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, fmt.Errorf("failed to commit api token: %w", err)
+	}
+
+	return true, nil
+}
+
+// Errors returned when an API token, dashboard login token or dashboard session
+// doesn't match a live one
+var (
+	ErrInvalidAPIToken   = errors.New("invalid api token")
+	ErrInvalidLoginToken = errors.New("invalid login token")
+	ErrSessionNotFound   = errors.New("session not found")
+)
+
+// GetUserByAPIToken returns the user owning an API token and records its use
+func GetUserByAPIToken(ctx context.Context, token string) (*User, error) {
+	query := `
+		UPDATE api_tokens t
+		SET last_used_at = NOW()
+		FROM users u
+		WHERE u.id = t.user_id AND t.token_hash = $1
+		RETURNING u.id, u.discord_id, u.username, u.discriminator, u.timezone, u.created_at, u.updated_at
+	`
+
+	user := &User{}
+	err := DB.QueryRowContext(ctx, query, hashToken(token)).Scan(
+		&user.ID,
+		&user.DiscordID,
+		&user.Username,
+		&user.Discriminator,
+		&user.Timezone,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, fmt.Errorf("failed to get user by api token: %w", err)
+	}
+
+	return user, nil
+}
+
+// CreateDashboardLoginToken issues a one-time dashboard login token for a user,
+// scoped to the guild the link was requested from
+func CreateDashboardLoginToken(ctx context.Context, userID int, guildID string, ttl time.Duration) (string, error) {
+	token, err := generateToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO dashboard_login_tokens (token_hash, user_id, guild_id, expires_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+	`
+
+	_, err = DB.ExecContext(ctx, query, hashToken(token), userID, guildID, time.Now().Add(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to create dashboard login token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ExchangeDashboardLoginToken consumes a one-time login token and starts a dashboard
+// session, returning the session token
+func ExchangeDashboardLoginToken(ctx context.Context, loginToken string, ttl time.Duration) (string, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin login transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID int
+	var guildID sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE dashboard_login_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id, guild_id
+	`, hashToken(loginToken)).Scan(&userID, &guildID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrInvalidLoginToken
+		}
+		return "", fmt.Errorf("failed to use dashboard login token: %w", err)
+	}
+
+	sessionToken, err := generateToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO dashboard_sessions (token_hash, user_id, guild_id, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, hashToken(sessionToken), userID, guildID, time.Now().Add(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to create dashboard session: %w", err)
+	}
+
+	// Clean up anything that can no longer be used
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM dashboard_login_tokens WHERE expires_at < NOW() - INTERVAL '1 day';
+		DELETE FROM dashboard_sessions WHERE expires_at < NOW();
+	`)
+	if err != nil {
+		return "", fmt.Errorf("failed to clean up dashboard logins: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", fmt.Errorf("failed to commit dashboard login: %w", err)
+	}
+
+	return sessionToken, nil
+}
+
+// GetDashboardSession returns the user and guild for a live dashboard session
+func GetDashboardSession(ctx context.Context, sessionToken string) (*User, string, error) {
+	query := `
+		SELECT u.id, u.discord_id, u.username, u.discriminator, u.timezone, u.created_at, u.updated_at,
+			COALESCE(s.guild_id, '')
+		FROM dashboard_sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash = $1 AND s.expires_at > NOW()
+	`
+
+	user := &User{}
+	var guildID string
+	err := DB.QueryRowContext(ctx, query, hashToken(sessionToken)).Scan(
+		&user.ID,
+		&user.DiscordID,
+		&user.Username,
+		&user.Discriminator,
+		&user.Timezone,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&guildID,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", ErrSessionNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get dashboard session: %w", err)
+	}
+
+	return user, guildID, nil
+}
+
+// DeleteDashboardSession ends a dashboard session
+func DeleteDashboardSession(ctx context.Context, sessionToken string) error {
+	_, err := DB.ExecContext(ctx, `DELETE FROM dashboard_sessions WHERE token_hash = $1`, hashToken(sessionToken))
+	if err != nil {
+		return fmt.Errorf("failed to delete dashboard session: %w", err)
+	}
+	return nil
+}
+
+// DefaultCategory is used when a game is recorded without a category
+const DefaultCategory = "Casual"
+
+// maxCategoryLength is the longest category name the category columns can hold
+const maxCategoryLength = 50
+
+// GameCategories are the default categories, in display order. Servers start with
+// these and can change them with /category.
+var GameCategories = []string{
+	"Casual",
+	"Ranked",
+	"Locals",
+	"Regional",
+	"National",
+	"Tournament",
+	"Practice",
+	"Online",
+}
+
+// ValidateCategory checks that a category name can be stored
+func ValidateCategory(category string) bool {
+	category = strings.TrimSpace(category)
+	return category != "" && len([]rune(category)) <= maxCategoryLength
+}
+
+// NormalizeCategory trims a category and gives the default categories their usual
+// capitalization. Other names, such as a server's own categories, are kept as given.
+func NormalizeCategory(category string) string {
+	category = strings.TrimSpace(category)
+	for _, valid := range GameCategories {
+		if strings.EqualFold(category, valid) {
+			return valid
+		}
+	}
+
+	return category
+}
+
+// GetGuildCategories returns the categories games can be recorded in on a server, in
+// display order. Servers that never changed their categories, and DMs (guildID ""),
+// get the defaults.
+func GetGuildCategories(ctx context.Context, guildID string) ([]string, error) {
+	if guildID == "" {
+		return GameCategories, nil
+	}
+
+	rows, err := DB.QueryContext(ctx, `
+		SELECT name
+		FROM guild_categories
+		WHERE guild_id = $1
+		ORDER BY id
+	`, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guild categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []string
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan guild category: %w", err)
+		}
+		categories = append(categories, name)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get guild categories: %w", err)
+	}
+
+	if len(categories) == 0 {
+		return GameCategories, nil
+	}
+	return categories, nil
+}
+
+// Errors returned by RemoveGuildCategory
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrLastCategory     = errors.New("last category")
+)
+
+// seedGuildCategories copies the default categories to a server that has none yet,
+// so its first change starts from the list it was already using
+func seedGuildCategories(ctx context.Context, tx *sql.Tx, guildID string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO guild_categories (guild_id, name)
+		SELECT $1, name
+		FROM unnest($2::text[]) WITH ORDINALITY AS defaults(name, position)
+		WHERE NOT EXISTS (SELECT 1 FROM guild_categories WHERE guild_id = $1)
+		ORDER BY position
+		ON CONFLICT (guild_id, LOWER(name)) DO NOTHING
+	`, guildID, pq.Array(GameCategories))
+	if err != nil {
+		return fmt.Errorf("failed to seed guild categories: %w", err)
+	}
+	return nil
+}
+
+// AddGuildCategory adds a category to a server. It returns false if the server already
+// has a category with that name, ignoring case.
+func AddGuildCategory(ctx context.Context, guildID, name string) (bool, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin category transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = seedGuildCategories(ctx, tx, guildID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO guild_categories (guild_id, name)
+		VALUES ($1, $2)
+		ON CONFLICT (guild_id, LOWER(name)) DO NOTHING
+	`, guildID, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to add guild category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	err = recordAudit(ctx, tx, AuditActionCategoryAdded, "category", 0, nil, map[string]string{"name": name})
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, fmt.Errorf("failed to commit guild category: %w", err)
+	}
+
+	return true, nil
+}
+
+// RemoveGuildCategory removes a category from a server, matching the name without
+// regard to case, and returns the name as it was stored. Games already recorded in
+// the category keep it. A server must keep at least one category.
+func RemoveGuildCategory(ctx context.Context, guildID, name string) (string, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin category transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = seedGuildCategories(ctx, tx, guildID)
+	if err != nil {
+		return "", err
+	}
+
+	var removed string
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM guild_categories
+		WHERE guild_id = $1 AND LOWER(name) = LOWER($2)
+		RETURNING name
+	`, guildID, name).Scan(&removed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrCategoryNotFound
+		}
+		return "", fmt.Errorf("failed to remove guild category: %w", err)
+	}
+
+	var remaining int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM guild_categories WHERE guild_id = $1`, guildID).Scan(&remaining)
+	if err != nil {
+		return "", fmt.Errorf("failed to count guild categories: %w", err)
+	}
+	if remaining == 0 {
+		return "", ErrLastCategory
+	}
+
+	err = recordAudit(ctx, tx, AuditActionCategoryRemoved, "category", 0, map[string]string{"name": removed}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", fmt.Errorf("failed to commit guild category: %w", err)
+	}
+
+	return removed, nil
+}
+
+// Audit log actions. Rating updates after a confirmation, dashboard logins and game
+// group activity bumps aren't audited: the first follow from game.resolved, and the
+// others are churn that would drown out the changes admins look for.
+const (
+	AuditActionUserCreated       = "user.created"
+	AuditActionTimezoneUpdated   = "user.timezone_updated"
+	AuditActionUserForgotten     = "user.forgotten"
+	AuditActionGameCreated       = "game.created"
+	AuditActionGameResolved      = "game.resolved"
+	AuditActionRatingsRecomputed = "ratings.recomputed"
+	AuditActionAPITokenCreated   = "api_token.created"
+	AuditActionAPITokenRevoked   = "api_token.revoked"
+	AuditActionCategoryAdded     = "category.added"
+	AuditActionCategoryRemoved   = "category.removed"
+	AuditActionGameGroupCreated  = "game_group.created"
+	AuditActionGameGroupArchived = "game_group.archived"
+	AuditActionGameGroupDeleted  = "game_group.deleted"
+	AuditActionLFGJoined         = "lfg.joined"
+	AuditActionLFGLeft           = "lfg.left"
+	AuditActionLFGMatched        = "lfg.matched"
+	AuditActionLFGChannelOpened  = "lfg.channel_opened"
+	AuditActionLFGMatchCancelled = "lfg.match_cancelled"
+	AuditActionLFGResultRecorded = "lfg.result_recorded"
+	AuditActionLFGMatchClosed    = "lfg.match_closed"
+	AuditActionSessionStarted    = "session.started"
+	AuditActionSessionStopped    = "session.stopped"
+)
+
+// AuditActions are all audit log actions, in the order they are offered to admins
+var AuditActions = []string{
+	AuditActionGameCreated,
+	AuditActionGameResolved,
+	AuditActionCategoryAdded,
+	AuditActionCategoryRemoved,
+	AuditActionRatingsRecomputed,
+	AuditActionGameGroupCreated,
+	AuditActionGameGroupArchived,
+	AuditActionGameGroupDeleted,
+	AuditActionLFGJoined,
+	AuditActionLFGLeft,
+	AuditActionLFGMatched,
+	AuditActionLFGChannelOpened,
+	AuditActionLFGMatchCancelled,
+	AuditActionLFGResultRecorded,
+	AuditActionLFGMatchClosed,
+	AuditActionSessionStarted,
+	AuditActionSessionStopped,
+	AuditActionUserCreated,
+	AuditActionTimezoneUpdated,
+	AuditActionAPITokenCreated,
+	AuditActionAPITokenRevoked,
+	AuditActionUserForgotten,
+}
+
+// AuditEntry is one change recorded in the audit log. Before and After are JSON
+// snapshots of the target, when the change has one.
+type AuditEntry struct {
+	ID          int    `json:"id"`
+	Action      string `json:"action"`
+	ActorUserID *int   `json:"actor_user_id,omitempty"`
+	// ActorDiscordID is filled in when reading entries, while the actor still exists
+	ActorDiscordID string          `json:"actor_discord_id,omitempty"`
+	GuildID        string          `json:"guild_id,omitempty"`
+	TargetType     string          `json:"target_type,omitempty"`
+	TargetID       *int            `json:"target_id,omitempty"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	InteractionID  string          `json:"interaction_id,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// auditEntryColumns lists the audit_log columns in the order scanAuditEntry expects them
+// from audit_log a LEFT JOIN users u ON u.id = a.actor_user_id
+const auditEntryColumns = `a.id, a.action, a.actor_user_id, COALESCE(u.discord_id, ''), COALESCE(a.guild_id, ''),
+	COALESCE(a.target_type, ''), a.target_id, a.before, a.after, COALESCE(a.interaction_id, ''), a.created_at`
+
+// scanAuditEntry scans a row selected with auditEntryColumns
+func scanAuditEntry(row rowScanner) (*AuditEntry, error) {
+	entry := &AuditEntry{}
+	var actorUserID, targetID sql.NullInt64
+	var before, after []byte
+	err := row.Scan(
+		&entry.ID,
+		&entry.Action,
+		&actorUserID,
+		&entry.ActorDiscordID,
+		&entry.GuildID,
+		&entry.TargetType,
+		&targetID,
+		&before,
+		&after,
+		&entry.InteractionID,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorUserID.Valid {
+		id := int(actorUserID.Int64)
+		entry.ActorUserID = &id
+	}
+	if targetID.Valid {
+		id := int(targetID.Int64)
+		entry.TargetID = &id
+	}
+	entry.Before = before
+	entry.After = after
+
+	return entry, nil
+}
+
+// nullJSON passes a JSON snapshot to a JSONB column, or NULL if there isn't one
+func nullJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+// insertAuditEntry writes an entry to the audit log as part of tx, so the entry is
+// only kept if the change it describes is. The actor is looked up by ActorDiscordID
+// when ActorUserID isn't set.
+func insertAuditEntry(ctx context.Context, tx *sql.Tx, entry *AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (action, actor_user_id, guild_id, target_type, target_id, before, after, interaction_id)
+		VALUES ($1, COALESCE($2::INTEGER, (SELECT id FROM users WHERE discord_id = $9)),
+			NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, NULLIF($8, ''))
+	`
+
+	_, err := tx.ExecContext(ctx, query, entry.Action, entry.ActorUserID, entry.GuildID, entry.TargetType, entry.TargetID,
+		nullJSON(entry.Before), nullJSON(entry.After), entry.InteractionID, entry.ActorDiscordID)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// recordAudit writes an audit entry for a change made in tx by whoever ctx says is
+// acting (see withAuditSource). before and after are snapshots of the target, encoded
+// as JSON; nil leaves them out. A targetID of 0 means the change has no single target.
+func recordAudit(ctx context.Context, tx *sql.Tx, action, targetType string, targetID int, before, after any) error {
+	source := auditSourceFrom(ctx)
+	entry := &AuditEntry{
+		Action:         action,
+		ActorDiscordID: source.ActorDiscordID,
+		GuildID:        source.GuildID,
+		TargetType:     targetType,
+		InteractionID:  source.InteractionID,
+	}
+	if targetID != 0 {
+		entry.TargetID = &targetID
+	}
+
+	var err error
+	if before != nil {
+		entry.Before, err = json.Marshal(before)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit snapshot: %w", err)
+		}
+	}
+	if after != nil {
+		entry.After, err = json.Marshal(after)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit snapshot: %w", err)
+		}
+	}
+
+	return insertAuditEntry(ctx, tx, entry)
+}
+
+// AuditFilter narrows GetAuditEntries. Zero values mean no restriction, apart from
+// GuildID which is required.
+type AuditFilter struct {
+	GuildID        string
+	ActorDiscordID string
+	Action         string
+	Limit          int
+}
+
+// GetAuditEntries returns a guild's most recent audit entries, newest first
+func GetAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error) {
+	query := `
+		SELECT ` + auditEntryColumns + `
+		FROM audit_log a
+		LEFT JOIN users u ON u.id = a.actor_user_id
+		WHERE a.guild_id = $1
+			AND ($2 = '' OR u.discord_id = $2)
+			AND ($3 = '' OR a.action = $3)
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT $4
+	`
+
+	rows, err := DB.QueryContext(ctx, query, filter.GuildID, filter.ActorDiscordID, filter.Action, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// APITokenRecord describes a user's API token without the token itself
+type APITokenRecord struct {
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// DashboardAccessRecord describes a dashboard login link or session without its token
+type DashboardAccessRecord struct {
+	GuildID   string     `json:"guild_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// UserDataExport is everything stored about a user, as sent by /my-data. Secret
+// tokens are only stored as hashes and are left out.
+type UserDataExport struct {
+	ExportedAt time.Time `json:"exported_at"`
+	User       *User     `json:"user"`
+	// Games are the games the user recorded and the games others recorded against them
+	Games             []*GameResult            `json:"games"`
+	Ratings           []*PlayerRating          `json:"ratings"`
+	APIToken          *APITokenRecord          `json:"api_token,omitempty"`
+	DashboardLogins   []*DashboardAccessRecord `json:"dashboard_logins"`
+	DashboardSessions []*DashboardAccessRecord `json:"dashboard_sessions"`
+	AuditEntries      []*AuditEntry            `json:"audit_entries"`
+	PracticeSessions  []*PracticeSession       `json:"practice_sessions"`
+	// GameGroups are the /create-game groups the user created
+	GameGroups []*GameGroup `json:"game_groups"`
+	LFGEntries []*LFGEntry  `json:"lfg_entries"`
+	// LFGMatches are the /lfg matches the user was paired into. Their entries are left
+	// out since the other entry is the opponent's.
+	LFGMatches []*LFGMatch `json:"lfg_matches"`
+}
+
+// ExportUserData collects everything stored about a user. It reads from a single
+// snapshot so the export is consistent.
+func ExportUserData(ctx context.Context, user *User) (*UserDataExport, error) {
+	tx, err := DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin export transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	export := &UserDataExport{ExportedAt: time.Now().UTC(), User: user}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+gameResultColumns+`
+		FROM game_results
+		WHERE user_id = $1 OR opponent_user_id = $1
+		ORDER BY created_at, id
+	`, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export games: %w", err)
+	}
+	for rows.Next() {
+		gameResult, err := scanGameResult(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan game result: %w", err)
+		}
+		export.Games = append(export.Games, gameResult)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export games: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT `+playerRatingColumns+`
+		FROM ratings r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.user_id = $1
+		ORDER BY r.category
+	`, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export ratings: %w", err)
+	}
+	for rows.Next() {
+		rating, err := scanPlayerRating(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		export.Ratings = append(export.Ratings, rating)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export ratings: %w", err)
+	}
+
+	token := &APITokenRecord{}
+	err = tx.QueryRowContext(ctx, `SELECT created_at, last_used_at FROM api_tokens WHERE user_id = $1`, user.ID).
+		Scan(&token.CreatedAt, &token.LastUsedAt)
+	if err == nil {
+		export.APIToken = token
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to export api token: %w", err)
+	}
+
+	export.DashboardLogins, err = exportDashboardAccess(ctx, tx, `
+		SELECT COALESCE(guild_id, ''), created_at, expires_at, used_at
+		FROM dashboard_login_tokens
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export dashboard logins: %w", err)
+	}
+
+	export.DashboardSessions, err = exportDashboardAccess(ctx, tx, `
+		SELECT COALESCE(guild_id, ''), created_at, expires_at, NULL
+		FROM dashboard_sessions
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export dashboard sessions: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT `+auditEntryColumns+`
+		FROM audit_log a
+		LEFT JOIN users u ON u.id = a.actor_user_id
+		WHERE a.actor_user_id = $1
+		ORDER BY a.created_at, a.id
+	`, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export audit entries: %w", err)
+	}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		export.AuditEntries = append(export.AuditEntries, entry)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export audit entries: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT `+practiceSessionColumns+`
+		FROM practice_sessions
+		WHERE user_id = $1
+		ORDER BY started_at, id
+	`, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export practice sessions: %w", err)
+	}
+	for rows.Next() {
+		session, err := scanPracticeSession(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan practice session: %w", err)
+		}
+		export.PracticeSessions = append(export.PracticeSessions, session)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export practice sessions: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT `+gameGroupColumns+`
+		FROM game_groups
+		WHERE creator_user_id = $1
+		ORDER BY created_at, id
+	`, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export game groups: %w", err)
+	}
+	for rows.Next() {
+		group, err := scanGameGroup(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan game group: %w", err)
+		}
+		export.GameGroups = append(export.GameGroups, group)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export game groups: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT `+lfgEntryColumns+`
+		FROM lfg_queue q
+		JOIN users u ON u.id = q.user_id
+		WHERE q.user_id = $1
+		ORDER BY q.created_at, q.id
+	`, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export lfg entries: %w", err)
+	}
+	for rows.Next() {
+		entry, err := scanLFGEntry(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan lfg entry: %w", err)
+		}
+		export.LFGEntries = append(export.LFGEntries, entry)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export lfg entries: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT `+lfgMatchColumns+`
+		FROM lfg_matches
+		WHERE id IN (SELECT match_id FROM lfg_queue WHERE user_id = $1)
+		ORDER BY created_at, id
+	`, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export lfg matches: %w", err)
+	}
+	for rows.Next() {
+		match, err := scanLFGMatch(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan lfg match: %w", err)
+		}
+		export.LFGMatches = append(export.LFGMatches, match)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export lfg matches: %w", err)
+	}
+
+	return export, nil
+}
+
+// exportDashboardAccess scans dashboard login links or sessions selected as guild ID,
+// creation, expiry and use times
+func exportDashboardAccess(ctx context.Context, tx *sql.Tx, query string, userID int) ([]*DashboardAccessRecord, error) {
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*DashboardAccessRecord
+	for rows.Next() {
+		record := &DashboardAccessRecord{}
+		err = rows.Scan(&record.GuildID, &record.CreatedAt, &record.ExpiresAt, &record.UsedAt)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// UserDeletion counts what DeleteUserData removed. It is what the audit log keeps
+// about a deleted user, so it must not hold anything that identifies them.
+type UserDeletion struct {
+	Games   int `json:"games"`
+	Ratings int `json:"ratings"`
+	// DetachedGames are games other members recorded against the user. They are kept
+	// for the members who recorded them, as games against a leader only.
+	DetachedGames int `json:"detached_games"`
+}
+
+// DeleteUserData deletes a user and everything stored about them, and records the
+// deletion in the audit log without any personal data. guildID is where the deletion
+// was requested from, if anywhere.
+func DeleteUserData(ctx context.Context, userID int, guildID string) (*UserDeletion, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin deletion transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deletion := &UserDeletion{}
+
+	// The opponent_user_id foreign key cascades, which would delete other members'
+	// games too. Detach them first; confirmed and pending games become plain
+	// recorded games, and disputed games stay out of stats.
+	result, err := tx.ExecContext(ctx, `
+		UPDATE game_results
+		SET opponent_user_id = NULL,
+			status = CASE WHEN status IN ($2, $3) THEN $4 ELSE status END
+		WHERE opponent_user_id = $1
+	`, userID, GameStatusConfirmed, GameStatusPending, GameStatusRecorded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detach opponent games: %w", err)
+	}
+	detached, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	deletion.DetachedGames = int(detached)
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT (SELECT COUNT(*) FROM game_results WHERE user_id = $1),
+			(SELECT COUNT(*) FROM ratings WHERE user_id = $1)
+	`, userID).Scan(&deletion.Games, &deletion.Ratings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count user data: %w", err)
+	}
+
+	// Audit entries are kept, but their snapshots of the user and their games go. The
+	// actor link is cleared by the foreign key.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE audit_log
+		SET before = NULL, after = NULL
+		WHERE actor_user_id = $1
+			OR (target_type = 'user' AND target_id = $1)
+			OR (target_type = 'game' AND target_id IN (SELECT id FROM game_results WHERE user_id = $1))
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear audit snapshots: %w", err)
+	}
+
+	// Everything else references users with ON DELETE CASCADE
+	result, err = tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete user: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if deleted == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	after, err := json.Marshal(deletion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode deletion: %w", err)
+	}
+	err = insertAuditEntry(ctx, tx, &AuditEntry{
+		Action:     AuditActionUserForgotten,
+		GuildID:    guildID,
+		TargetType: "user",
+		After:      after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit user deletion: %w", err)
+	}
+
+	return deletion, nil
+}
+
+// Game group statuses. Archived groups keep their channel, read-only.
+const (
+	GameGroupStatusActive   = "active"
+	GameGroupStatusArchived = "archived"
+)
+
+// Errors returned by the game group functions
+var (
+	ErrGameGroupExists    = errors.New("game group exists")
+	ErrGameGroupNotFound  = errors.New("game group not found")
+	ErrGameGroupNotActive = errors.New("game group not active")
+)
+
+// GameGroup is a /create-game group: a role and the private channel it can see
+type GameGroup struct {
+	ID             int        `json:"id"`
+	GuildID        string     `json:"guild_id"`
+	Name           string     `json:"name"`
+	RoleID         string     `json:"role_id"`
+	ChannelID      string     `json:"channel_id"`
+	CreatorUserID  *int       `json:"creator_user_id,omitempty"`
+	Status         string     `json:"status"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	ArchivedAt     *time.Time `json:"archived_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// gameGroupColumns lists the game_groups columns in the order scanGameGroup expects them
+const gameGroupColumns = `id, guild_id, name, role_id, channel_id, creator_user_id, status, last_activity_at, archived_at, created_at`
+
+// scanGameGroup scans a row selected with gameGroupColumns
+func scanGameGroup(row rowScanner) (*GameGroup, error) {
+	group := &GameGroup{}
+	var creatorUserID sql.NullInt64
+	err := row.Scan(
+		&group.ID,
+		&group.GuildID,
+		&group.Name,
+		&group.RoleID,
+		&group.ChannelID,
+		&creatorUserID,
+		&group.Status,
+		&group.LastActivityAt,
+		&group.ArchivedAt,
+		&group.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if creatorUserID.Valid {
+		id := int(creatorUserID.Int64)
+		group.CreatorUserID = &id
+	}
+
+	return group, nil
+}
+
+// CreateGameGroup records a game group whose role and channel were just created.
+// It returns ErrGameGroupExists if the server already has a group with that name.
+func CreateGameGroup(ctx context.Context, guildID, name, roleID, channelID string, creatorUserID int) (*GameGroup, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin game group transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO game_groups (guild_id, name, role_id, channel_id, creator_user_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (guild_id, LOWER(name)) DO NOTHING
+		RETURNING ` + gameGroupColumns
+
+	group, err := scanGameGroup(tx.QueryRowContext(ctx, query, guildID, name, roleID, channelID, creatorUserID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGameGroupExists
+		}
+		return nil, fmt.Errorf("failed to create game group: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionGameGroupCreated, "game_group", group.ID, nil, group)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit game group: %w", err)
+	}
+
+	return group, nil
+}
+
+// GetGameGroupByID retrieves a game group by its ID
+func GetGameGroupByID(ctx context.Context, id int) (*GameGroup, error) {
+	query := `SELECT ` + gameGroupColumns + ` FROM game_groups WHERE id = $1`
+
+	group, err := scanGameGroup(DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGameGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get game group: %w", err)
+	}
+
+	return group, nil
+}
+
+// GetGameGroup finds a server's game group by its channel ID or by name, ignoring case
+func GetGameGroup(ctx context.Context, guildID, channelIDOrName string) (*GameGroup, error) {
+	query := `
+		SELECT ` + gameGroupColumns + `
+		FROM game_groups
+		WHERE guild_id = $1 AND (channel_id = $2 OR LOWER(name) = LOWER($2))
+		ORDER BY channel_id = $2 DESC
+		LIMIT 1
+	`
+
+	group, err := scanGameGroup(DB.QueryRowContext(ctx, query, guildID, channelIDOrName))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGameGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get game group: %w", err)
+	}
+
+	return group, nil
+}
+
+// GetGuildGameGroupNames returns the names of a server's game groups, alphabetically
+func GetGuildGameGroupNames(ctx context.Context, guildID string) ([]string, error) {
+	rows, err := DB.QueryContext(ctx, `
+		SELECT name FROM game_groups
+		WHERE guild_id = $1
+		ORDER BY LOWER(name)
+	`, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game groups: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan game group: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get game groups: %w", err)
+	}
+
+	return names, nil
+}
+
+// GetActiveGameGroups returns every active game group, for the auto-archiver
+func GetActiveGameGroups(ctx context.Context) ([]*GameGroup, error) {
+	rows, err := DB.QueryContext(ctx, `SELECT `+gameGroupColumns+` FROM game_groups WHERE status = $1 ORDER BY id`, GameGroupStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active game groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*GameGroup
+	for rows.Next() {
+		group, err := scanGameGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan game group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get active game groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// TouchGameGroup marks a game group as active now, such as when someone joins it.
+// Activity bumps aren't audited; see the audit log actions.
+func TouchGameGroup(ctx context.Context, id int) error {
+	_, err := DB.ExecContext(ctx, `UPDATE game_groups SET last_activity_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to touch game group: %w", err)
+	}
+	return nil
+}
+
+// ArchiveGameGroup marks an active game group as archived. It returns
+// ErrGameGroupNotActive if it was already archived.
+func ArchiveGameGroup(ctx context.Context, id int) (*GameGroup, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin game group transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE game_groups
+		SET status = $1, archived_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING ` + gameGroupColumns
+
+	group, err := scanGameGroup(tx.QueryRowContext(ctx, query, GameGroupStatusArchived, id, GameGroupStatusActive))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGameGroupNotActive
+		}
+		return nil, fmt.Errorf("failed to archive game group: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionGameGroupArchived, "game_group", group.ID,
+		map[string]string{"status": GameGroupStatusActive}, map[string]string{"status": GameGroupStatusArchived})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit game group: %w", err)
+	}
+
+	return group, nil
+}
+
+// DeleteGameGroup removes a game group's record once its role and channel are gone
+func DeleteGameGroup(ctx context.Context, id int) error {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin game group transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	group, err := scanGameGroup(tx.QueryRowContext(ctx, `DELETE FROM game_groups WHERE id = $1 RETURNING `+gameGroupColumns, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrGameGroupNotFound
+		}
+		return fmt.Errorf("failed to delete game group: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionGameGroupDeleted, "game_group", group.ID, group, nil)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit game group: %w", err)
+	}
+
+	return nil
+}
+
+// LFG queue entry statuses
+const (
+	LFGStatusWaiting   = "waiting"
+	LFGStatusMatched   = "matched"
+	LFGStatusCancelled = "cancelled"
+)
+
+// Errors returned by the /lfg match functions
+var (
+	ErrLFGMatchNotFound = errors.New("lfg match not found")
+	ErrLFGMatchRecorded = errors.New("lfg match already recorded")
+)
+
+// LFGEntry is a member's place in a server's /lfg queue
+type LFGEntry struct {
+	ID        int    `json:"id"`
+	UserID    int    `json:"user_id"`
+	DiscordID string `json:"discord_id"`
+	GuildID   string `json:"guild_id"`
+	Leader    string `json:"leader"`
+	// WantedOpponents are the opponent leaders the member wants to practice against,
+	// lowercased. Empty means any.
+	WantedOpponents []string  `json:"wanted_opponents"`
+	Category        string    `json:"category"`
+	AvailableUntil  time.Time `json:"available_until"`
+	Status          string    `json:"status"`
+	MatchID         *int      `json:"match_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// lfgLeaderKey is how leader names are compared when pairing queue entries
+func lfgLeaderKey(leader string) string {
+	return strings.ToLower(strings.TrimSpace(leader))
+}
+
+// Wants reports whether the member would practice against a leader
+func (e *LFGEntry) Wants(leader string) bool {
+	if len(e.WantedOpponents) == 0 {
+		return true
+	}
+	key := lfgLeaderKey(leader)
+	for _, opponent := range e.WantedOpponents {
+		if lfgLeaderKey(opponent) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Compatible reports whether two queue entries can be paired: they queued for the same
+// category and each one's leader is among the opponents the other wants
+func (e *LFGEntry) Compatible(other *LFGEntry) bool {
+	return e.Category == other.Category && e.Wants(other.Leader) && other.Wants(e.Leader)
+}
+
+// lfgEntryColumns lists the lfg_queue columns in the order scanLFGEntry expects them
+// from lfg_queue q JOIN users u ON u.id = q.user_id
+const lfgEntryColumns = `q.id, q.user_id, u.discord_id, q.guild_id, q.leader, q.wanted_opponents, q.category,
+	q.available_until, q.status, q.match_id, q.created_at`
+
+// scanLFGEntry scans a row selected with lfgEntryColumns
+func scanLFGEntry(row rowScanner) (*LFGEntry, error) {
+	entry := &LFGEntry{}
+	var matchID sql.NullInt64
+	err := row.Scan(
+		&entry.ID,
+		&entry.UserID,
+		&entry.DiscordID,
+		&entry.GuildID,
+		&entry.Leader,
+		pq.Array(&entry.WantedOpponents),
+		&entry.Category,
+		&entry.AvailableUntil,
+		&entry.Status,
+		&matchID,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if matchID.Valid {
+		id := int(matchID.Int64)
+		entry.MatchID = &id
+	}
+
+	return entry, nil
+}
+
+// LFGMatch pairs two queue entries. ChannelID and RoleID are empty until the match's
+// temporary channel has been created.
+type LFGMatch struct {
+	ID           int        `json:"id"`
+	GuildID      string     `json:"guild_id"`
+	Category     string     `json:"category"`
+	ChannelID    string     `json:"channel_id,omitempty"`
+	RoleID       string     `json:"role_id,omitempty"`
+	GameResultID *int       `json:"game_result_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+	// Entries are the two paired queue entries, the one that was waiting first
+	Entries []*LFGEntry `json:"entries"`
+}
+
+// Entry returns the match's entry for a user, or nil if they aren't in the match
+func (m *LFGMatch) Entry(userID int) *LFGEntry {
+	for _, entry := range m.Entries {
+		if entry.UserID == userID {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Opponent returns the other entry in the match
+func (m *LFGMatch) Opponent(userID int) *LFGEntry {
+	for _, entry := range m.Entries {
+		if entry.UserID != userID {
+			return entry
+		}
+	}
+	return nil
+}
+
+// lfgMatchColumns lists the lfg_matches columns in the order scanLFGMatch expects them
+const lfgMatchColumns = `id, guild_id, category, COALESCE(channel_id, ''), COALESCE(role_id, ''), game_result_id, created_at, closed_at`
+
+// scanLFGMatch scans a row selected with lfgMatchColumns
+func scanLFGMatch(row rowScanner) (*LFGMatch, error) {
+	match := &LFGMatch{}
+	var gameResultID sql.NullInt64
+	err := row.Scan(
+		&match.ID,
+		&match.GuildID,
+		&match.Category,
+		&match.ChannelID,
+		&match.RoleID,
+		&gameResultID,
+		&match.CreatedAt,
+		&match.ClosedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if gameResultID.Valid {
+		id := int(gameResultID.Int64)
+		match.GameResultID = &id
+	}
+
+	return match, nil
+}
+
+// loadLFGMatchEntries fills in a match's entries, oldest first
+func loadLFGMatchEntries(ctx context.Context, q interface {
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+}, match *LFGMatch) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+lfgEntryColumns+`
+		FROM lfg_queue q
+		JOIN users u ON u.id = q.user_id
+		WHERE q.match_id = $1
+		ORDER BY q.created_at, q.id
+	`, match.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get lfg match entries: %w", err)
+	}
+	defer rows.Close()
+
+	match.Entries = nil
+	for rows.Next() {
+		entry, err := scanLFGEntry(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan lfg entry: %w", err)
+		}
+		match.Entries = append(match.Entries, entry)
+	}
+
+	return rows.Err()
+}
+
+// findLFGPartner returns the ID of the longest waiting entry in the joining member's
+// server queue that is compatible with them, and false if there is none
+func findLFGPartner(ctx context.Context, tx *sql.Tx, joining *LFGEntry) (int, bool, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+lfgEntryColumns+`
+		FROM lfg_queue q
+		JOIN users u ON u.id = q.user_id
+		WHERE q.guild_id = $1 AND q.status = $2 AND q.available_until > NOW()
+			AND q.user_id <> $3 AND q.category = $4
+		ORDER BY q.created_at, q.id
+	`, joining.GuildID, LFGStatusWaiting, joining.UserID, joining.Category)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find lfg partner: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanLFGEntry(rows)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to scan lfg entry: %w", err)
+		}
+		if entry.Compatible(joining) {
+			return entry.ID, true, nil
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return 0, false, fmt.Errorf("failed to find lfg partner: %w", err)
+	}
+
+	return 0, false, nil
+}
+
+// JoinLFGQueue puts a member in a server's queue, replacing their previous entry
+// there, and pairs them with the longest waiting compatible member if there is one,
+// as decided by LFGEntry.Compatible. It returns
+// the new entry and, if paired, the match, whose channel is still to be created.
+func JoinLFGQueue(ctx context.Context, userID int, guildID, leader string, wantedOpponents []string, category string, availableUntil time.Time) (*LFGEntry, *LFGMatch, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin lfg transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Joins to a server's queue take turns, so two compatible members joining at once
+	// can't both miss each other
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('lfg:' || $1::text))`, guildID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock lfg queue: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE lfg_queue SET status = $1
+		WHERE user_id = $2 AND guild_id = $3 AND status = $4
+	`, LFGStatusCancelled, userID, guildID, LFGStatusWaiting)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to replace lfg entry: %w", err)
+	}
+
+	// Stored lowercased, and as an empty array rather than NULL when any will do
+	wanted := make([]string, 0, len(wantedOpponents))
+	for _, opponent := range wantedOpponents {
+		wanted = append(wanted, lfgLeaderKey(opponent))
+	}
+
+	partnerID, paired, err := findLFGPartner(ctx, tx, &LFGEntry{
+		UserID:          userID,
+		GuildID:         guildID,
+		Leader:          leader,
+		WantedOpponents: wanted,
+		Category:        category,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var match *LFGMatch
+	status := LFGStatusWaiting
+	var matchID *int
+	if paired {
+		match, err = scanLFGMatch(tx.QueryRowContext(ctx, `
+			INSERT INTO lfg_matches (guild_id, category)
+			VALUES ($1, $2)
+			RETURNING `+lfgMatchColumns, guildID, category))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create lfg match: %w", err)
+		}
+		status = LFGStatusMatched
+		matchID = &match.ID
+
+		_, err = tx.ExecContext(ctx, `UPDATE lfg_queue SET status = $1, match_id = $2 WHERE id = $3`, LFGStatusMatched, match.ID, partnerID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to pair lfg entry: %w", err)
+		}
+	}
+
+	var entryID int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO lfg_queue (user_id, guild_id, leader, wanted_opponents, category, available_until, status, match_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, userID, guildID, leader, pq.Array(wanted), category, availableUntil, status, matchID).Scan(&entryID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to join lfg queue: %w", err)
+	}
+
+	entry, err := scanLFGEntry(tx.QueryRowContext(ctx, `
+		SELECT `+lfgEntryColumns+`
+		FROM lfg_queue q
+		JOIN users u ON u.id = q.user_id
+		WHERE q.id = $1
+	`, entryID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get lfg entry: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionLFGJoined, "lfg_entry", entry.ID, nil, entry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if paired {
+		err = loadLFGMatchEntries(ctx, tx, match)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = recordAudit(ctx, tx, AuditActionLFGMatched, "lfg_match", match.ID, nil, match)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to commit lfg entry: %w", err)
+	}
+
+	return entry, match, nil
+}
+
+// LeaveLFGQueue takes a member out of a server's queue, reporting whether they were
+// waiting in it
+func LeaveLFGQueue(ctx context.Context, userID int, guildID string) (bool, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin lfg transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var entryID int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE lfg_queue SET status = $1
+		WHERE user_id = $2 AND guild_id = $3 AND status = $4
+		RETURNING id
+	`, LFGStatusCancelled, userID, guildID, LFGStatusWaiting).Scan(&entryID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to leave lfg queue: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionLFGLeft, "lfg_entry", entryID,
+		map[string]string{"status": LFGStatusWaiting}, map[string]string{"status": LFGStatusCancelled})
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, fmt.Errorf("failed to commit lfg entry: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetLFGQueue returns the members waiting in a server's queue whose window is still
+// open, longest waiting first
+func GetLFGQueue(ctx context.Context, guildID string) ([]*LFGEntry, error) {
+	rows, err := DB.QueryContext(ctx, `
+		SELECT `+lfgEntryColumns+`
+		FROM lfg_queue q
+		JOIN users u ON u.id = q.user_id
+		WHERE q.guild_id = $1 AND q.status = $2 AND q.available_until > NOW()
+		ORDER BY q.created_at, q.id
+	`, guildID, LFGStatusWaiting)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lfg queue: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*LFGEntry
+	for rows.Next() {
+		entry, err := scanLFGEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lfg entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get lfg queue: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetLFGMatch retrieves a match and its entries
+func GetLFGMatch(ctx context.Context, id int) (*LFGMatch, error) {
+	match, err := scanLFGMatch(DB.QueryRowContext(ctx, `SELECT `+lfgMatchColumns+` FROM lfg_matches WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrLFGMatchNotFound
+		}
+		return nil, fmt.Errorf("failed to get lfg match: %w", err)
+	}
+
+	err = loadLFGMatchEntries(ctx, DB, match)
+	if err != nil {
+		return nil, err
+	}
+
+	return match, nil
+}
+
+// SetLFGMatchChannel records the temporary channel and role created for a match
+func SetLFGMatchChannel(ctx context.Context, id int, channelID, roleID string) error {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin lfg transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE lfg_matches SET channel_id = $1, role_id = $2 WHERE id = $3`, channelID, roleID, id)
+	if err != nil {
+		return fmt.Errorf("failed to set lfg match channel: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionLFGChannelOpened, "lfg_match", id,
+		nil, map[string]string{"channel_id": channelID, "role_id": roleID})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit lfg match: %w", err)
+	}
+
+	return nil
+}
+
+// CancelLFGMatch undoes a match whose channel couldn't be created, putting both
+// members back in the queue as they were
+func CancelLFGMatch(ctx context.Context, id int) error {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin lfg transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE lfg_queue SET status = $1, match_id = NULL WHERE match_id = $2`, LFGStatusWaiting, id)
+	if err != nil {
+		return fmt.Errorf("failed to requeue lfg entries: %w", err)
+	}
+
+	match, err := scanLFGMatch(tx.QueryRowContext(ctx, `DELETE FROM lfg_matches WHERE id = $1 RETURNING `+lfgMatchColumns, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrLFGMatchNotFound
+		}
+		return fmt.Errorf("failed to delete lfg match: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionLFGMatchCancelled, "lfg_match", match.ID, match, nil)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit lfg match: %w", err)
+	}
+
+	return nil
+}
+
+// RecordLFGMatchGame records a match's result as a pending player game against the
+// other player. The game is only kept if it's the match's first, so two players
+// submitting at once can't both record one: the second gets ErrLFGMatchRecorded.
+func RecordLFGMatchGame(ctx context.Context, matchID, userID, opponentUserID int, guildID, leader, opponent, category string, wentFirst bool, outcome Outcome) (*GameResult, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin lfg transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	gameResult, err := insertPlayerGameResult(ctx, tx, userID, opponentUserID, guildID, leader, opponent, category, wentFirst, outcome, GameDetails{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Waits on a concurrent claim of the same match, then finds game_result_id set
+	result, err := tx.ExecContext(ctx, `
+		UPDATE lfg_matches SET game_result_id = $1
+		WHERE id = $2 AND game_result_id IS NULL
+	`, gameResult.ID, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link lfg match game: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrLFGMatchRecorded
+	}
+
+	err = recordAudit(ctx, tx, AuditActionLFGResultRecorded, "lfg_match", matchID,
+		nil, map[string]int{"game_result_id": gameResult.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit lfg match game: %w", err)
+	}
+
+	gamesRecordedTotal.Inc(categoryMetricLabel(gameResult.Category))
+
+	return gameResult, nil
+}
+
+// GetStaleLFGMatches returns open matches created before the cutoff, whose
+// channels are due to be removed
+func GetStaleLFGMatches(ctx context.Context, before time.Time) ([]*LFGMatch, error) {
+	rows, err := DB.QueryContext(ctx, `
+		SELECT `+lfgMatchColumns+`
+		FROM lfg_matches
+		WHERE closed_at IS NULL AND created_at < $1
+		ORDER BY id
+	`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale lfg matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*LFGMatch
+	for rows.Next() {
+		match, err := scanLFGMatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lfg match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get stale lfg matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// CloseLFGMatch marks a match closed once its channel and role are gone
+func CloseLFGMatch(ctx context.Context, id int) error {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin lfg transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var closedAt time.Time
+	err = tx.QueryRowContext(ctx, `UPDATE lfg_matches SET closed_at = NOW() WHERE id = $1 RETURNING closed_at`, id).Scan(&closedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrLFGMatchNotFound
+		}
+		return fmt.Errorf("failed to close lfg match: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionLFGMatchClosed, "lfg_match", id,
+		map[string]any{"closed_at": nil}, map[string]any{"closed_at": closedAt})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit lfg match: %w", err)
+	}
+
+	return nil
+}
+
+const (
+	// MaxSessionLength is how long a practice session can run. A session left open
+	// longer is treated as having ended then, so a forgotten /session stop doesn't
+	// count as days of practice or collect games recorded long after.
+	MaxSessionLength = 12 * time.Hour
+	// maxSessionInterval is MaxSessionLength in SQL
+	maxSessionInterval = `INTERVAL '12 hours'`
+
+	// sessionEndExpr is when a practice_sessions row ended: its ended_at, or for a
+	// session still open, now or when it ran out, whichever is earlier
+	sessionEndExpr = `COALESCE(ended_at, LEAST(NOW(), started_at + ` + maxSessionInterval + `))`
+
+	// openSessionIDQuery is the user's open practice session, if any, for linking a
+	// game being inserted. It expects the user ID as query parameter $1.
+	openSessionIDQuery = `(SELECT id FROM practice_sessions
+		WHERE user_id = $1 AND ended_at IS NULL AND started_at > NOW() - ` + maxSessionInterval + `)`
+)
+
+// ErrNoOpenSession is returned when a user has no practice session running
+var ErrNoOpenSession = errors.New("no open session")
+
+// PracticeSession is a timed block of practice started with /session start
+type PracticeSession struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	GuildID   string    `json:"guild_id,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	// EndedAt is when the session was stopped, or nil while it's open
+	EndedAt *time.Time `json:"ended_at,omitempty"`
+}
+
+// Duration is how long the session ran, or has run so far, capped at MaxSessionLength
+func (s *PracticeSession) Duration() time.Duration {
+	end := time.Now()
+	if s.EndedAt != nil {
+		end = *s.EndedAt
+	}
+	return min(end.Sub(s.StartedAt), MaxSessionLength)
+}
+
+// practiceSessionColumns lists the practice_sessions columns in the order
+// scanPracticeSession expects them
+const practiceSessionColumns = `id, user_id, COALESCE(guild_id, ''), started_at, ended_at`
+
+// scanPracticeSession scans a row selected with practiceSessionColumns
+func scanPracticeSession(row rowScanner) (*PracticeSession, error) {
+	session := &PracticeSession{}
+	err := row.Scan(&session.ID, &session.UserID, &session.GuildID, &session.StartedAt, &session.EndedAt)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// closeExpiredPracticeSession ends a user's open session if it has run out, at the
+// moment it did, and returns it. It returns nil if they had no such session.
+func closeExpiredPracticeSession(ctx context.Context, tx *sql.Tx, userID int) (*PracticeSession, error) {
+	session, err := scanPracticeSession(tx.QueryRowContext(ctx, `
+		UPDATE practice_sessions SET ended_at = started_at + `+maxSessionInterval+`
+		WHERE user_id = $1 AND ended_at IS NULL AND started_at <= NOW() - `+maxSessionInterval+`
+		RETURNING `+practiceSessionColumns, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to close expired practice session: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionSessionStopped, "practice_session", session.ID,
+		map[string]any{"ended_at": nil}, map[string]any{"ended_at": session.EndedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// StartPracticeSession opens a practice session for a user and returns it with true.
+// If they haven't stopped their last one, it returns that open session with false.
+func StartPracticeSession(ctx context.Context, userID int, guildID string) (*PracticeSession, bool, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin session transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = closeExpiredPracticeSession(ctx, tx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	session, err := scanPracticeSession(tx.QueryRowContext(ctx, `
+		INSERT INTO practice_sessions (user_id, guild_id)
+		VALUES ($1, NULLIF($2, ''))
+		ON CONFLICT (user_id) WHERE ended_at IS NULL DO NOTHING
+		RETURNING `+practiceSessionColumns, userID, guildID))
+	if err == sql.ErrNoRows {
+		open, err := scanPracticeSession(tx.QueryRowContext(ctx, `
+			SELECT `+practiceSessionColumns+` FROM practice_sessions WHERE user_id = $1 AND ended_at IS NULL
+		`, userID))
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get open practice session: %w", err)
+		}
+		// Commit so an expired session is still closed
+		err = tx.Commit()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to commit practice session: %w", err)
+		}
+		return open, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to start practice session: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, AuditActionSessionStarted, "practice_session", session.ID, nil, session)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to commit practice session: %w", err)
+	}
+
+	return session, true, nil
+}
+
+// StopPracticeSession ends a user's open practice session. A session that already
+// ran out is ended when it did and still returned, so it can be reported on. It
+// returns ErrNoOpenSession if they don't have one.
+func StopPracticeSession(ctx context.Context, userID int) (*PracticeSession, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin session transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	session, err := closeExpiredPracticeSession(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session == nil {
+		session, err = scanPracticeSession(tx.QueryRowContext(ctx, `
+			UPDATE practice_sessions SET ended_at = NOW()
+			WHERE user_id = $1 AND ended_at IS NULL
+			RETURNING `+practiceSessionColumns, userID))
+		if err == sql.ErrNoRows {
+			return nil, ErrNoOpenSession
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to stop practice session: %w", err)
+		}
+
+		err = recordAudit(ctx, tx, AuditActionSessionStopped, "practice_session", session.ID,
+			map[string]any{"ended_at": nil}, map[string]any{"ended_at": session.EndedAt})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit practice session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetOpenPracticeSession returns a user's open practice session. It returns
+// ErrNoOpenSession if they don't have one or it ran out.
+func GetOpenPracticeSession(ctx context.Context, userID int) (*PracticeSession, error) {
+	session, err := scanPracticeSession(DB.QueryRowContext(ctx, `
+		SELECT `+practiceSessionColumns+`
+		FROM practice_sessions
+		WHERE user_id = $1 AND ended_at IS NULL AND started_at > NOW() - `+maxSessionInterval, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoOpenSession
+		}
+		return nil, fmt.Errorf("failed to get open practice session: %w", err)
+	}
+	return session, nil
+}
+
+// SessionReport is the record of the games played during a practice session
+type SessionReport struct {
+	Games  int `json:"games"`
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+	// Leaders are the leaders played in the session, most played first
+	Leaders []string `json:"leaders"`
+}
+
+// GetPracticeSessionReport returns the record of the user's games recorded while a
+// session ran. Going by player_games rather than session_id counts games other members
+// recorded against them too, once confirmed. Disputed games are left out; pending
+// ones they recorded count, since the session just ended.
+func GetPracticeSessionReport(ctx context.Context, sessionID int) (*SessionReport, error) {
+	rows, err := DB.QueryContext(ctx, `
+		SELECT MODE() WITHIN GROUP (ORDER BY TRIM(p.leader)) AS leader,
+			COUNT(*) AS games,
+			COUNT(*) FILTER (WHERE `+winOutcomeFilter+`) AS wins,
+			COUNT(*) FILTER (WHERE `+lossOutcomeFilter+`) AS losses,
+			COUNT(*) FILTER (WHERE `+drawOutcomeFilter+`) AS draws
+		FROM player_games p
+		JOIN practice_sessions s ON s.user_id = p.user_id
+		WHERE s.id = $1 AND p.status <> $2
+			AND p.created_at >= s.started_at AND p.created_at <= `+sessionEndExpr+`
+		GROUP BY LOWER(TRIM(p.leader))
+		ORDER BY games DESC, leader
+	`, sessionID, GameStatusDisputed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session report: %w", err)
+	}
+	defer rows.Close()
+
+	report := &SessionReport{}
+	for rows.Next() {
+		var leader string
+		var games, wins, losses, draws int
+		err = rows.Scan(&leader, &games, &wins, &losses, &draws)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session leader: %w", err)
+		}
+		report.Games += games
+		report.Wins += wins
+		report.Losses += losses
+		report.Draws += draws
+		report.Leaders = append(report.Leaders, leader)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get session report: %w", err)
+	}
+
+	return report, nil
+}
+
+// PracticeTime is the time a user spent in practice sessions over a window
+type PracticeTime struct {
+	Sessions int `json:"sessions"`
+	Minutes  int `json:"minutes"`
+}
+
+// GetUserPracticeTime totals the user's practice sessions overlapping [from, to),
+// counting only the part inside the window. A zero from or to leaves that side
+// unrestricted.
+func GetUserPracticeTime(ctx context.Context, userID int, from, to time.Time) (*PracticeTime, error) {
+	var fromArg, toArg *time.Time
+	if !from.IsZero() {
+		fromArg = &from
+	}
+	if !to.IsZero() {
+		toArg = &to
+	}
+
+	var seconds float64
+	practice := &PracticeTime{}
+	err := DB.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COALESCE(SUM(EXTRACT(EPOCH FROM
+				LEAST(`+sessionEndExpr+`, COALESCE($3::timestamptz, 'infinity'))
+				- GREATEST(started_at, COALESCE($2::timestamptz, '-infinity')))), 0)
+		FROM practice_sessions
+		WHERE user_id = $1
+			AND ($2::timestamptz IS NULL OR `+sessionEndExpr+` > $2)
+			AND ($3::timestamptz IS NULL OR started_at < $3)
+	`, userID, fromArg, toArg).Scan(&practice.Sessions, &seconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get practice time: %w", err)
+	}
+
+	practice.Minutes = int(seconds / 60)
+	return practice, nil
+}
+
+// GetUserSessionDays returns the days, in the given timezone, on which the user
+// started a practice session, oldest first
+func GetUserSessionDays(ctx context.Context, userID int, timezone string) ([]time.Time, error) {
+	rows, err := DB.QueryContext(ctx, `
+		SELECT DISTINCT (started_at AT TIME ZONE $2)::date AS day
+		FROM practice_sessions
+		WHERE user_id = $1
+		ORDER BY day
+	`, userID, timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []time.Time
+	for rows.Next() {
+		var day time.Time
+		err = rows.Scan(&day)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session day: %w", err)
+		}
+		days = append(days, day)
 	}
 
-	normalized, exists := validCategories[strings.ToLower(category)]
-	if exists {
-		return normalized
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get session days: %w", err)
 	}
 
-	return "Casual" // Default fallback
+	return days, nil
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// countingScanner records how many destinations a scan function passes to Scan
+type countingScanner struct {
+	dest int
+}
+
+func (s *countingScanner) Scan(dest ...any) error {
+	s.dest = len(dest)
+	return errors.New("counted")
+}
+
+// selectListLength counts the expressions in a SELECT list, ignoring commas inside
+// function calls such as COALESCE
+func selectListLength(columns string) int {
+	depth, count := 0, 1
+	for _, r := range columns {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestColumnListsMatchScanners(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string
+		scan    func(row rowScanner) error
+	}{
+		{"gameResultColumns", gameResultColumns, func(row rowScanner) error { _, err := scanGameResult(row); return err }},
+		{"playerGameColumns", playerGameColumns, func(row rowScanner) error { _, err := scanGameResult(row); return err }},
+		{"playerRatingColumns", playerRatingColumns, func(row rowScanner) error { _, err := scanPlayerRating(row); return err }},
+		{"auditEntryColumns", auditEntryColumns, func(row rowScanner) error { _, err := scanAuditEntry(row); return err }},
+		{"gameGroupColumns", gameGroupColumns, func(row rowScanner) error { _, err := scanGameGroup(row); return err }},
+		{"lfgEntryColumns", lfgEntryColumns, func(row rowScanner) error { _, err := scanLFGEntry(row); return err }},
+		{"lfgMatchColumns", lfgMatchColumns, func(row rowScanner) error { _, err := scanLFGMatch(row); return err }},
+		{"practiceSessionColumns", practiceSessionColumns, func(row rowScanner) error { _, err := scanPracticeSession(row); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := &countingScanner{}
+			tt.scan(row)
+
+			if want := selectListLength(tt.columns); row.dest != want {
+				t.Errorf("scanner reads %d columns, %s selects %d", row.dest, tt.name, want)
+			}
+		})
+	}
+}
+
+func TestPlayerGameColumnsMatchGameResultColumns(t *testing.T) {
+	// player_games renames id to game_id; every other column must line up
+	gameColumns := strings.Replace(gameResultColumns, "id, ", "game_id, ", 1)
+	if gameColumns != playerGameColumns {
+		t.Errorf("playerGameColumns = %q, want %q", playerGameColumns, gameColumns)
+	}
+}
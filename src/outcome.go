@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Outcome is how a game ended, from the perspective of the player it belongs to.
+//
+// How each outcome counts:
+//   - win counts as a win
+//   - loss, concession and penalty count as losses
+//   - draw and intentional_draw count as draws
+//
+// Every outcome counts as a game played. Win rate is wins out of decided games
+// (wins plus losses), so draws move it neither up nor down. For Glicko-2 ratings a
+// win scores 1, a loss or concession 0 and a draw 0.5; intentional draws and
+// penalties aren't rated, since neither says anything about how the game was played.
+type Outcome string
+
+const (
+	OutcomeWin  Outcome = "win"
+	OutcomeLoss Outcome = "loss"
+	// OutcomeDraw is a game that ended without a winner, such as at time in a round
+	OutcomeDraw Outcome = "draw"
+	// OutcomeIntentionalDraw is a draw both players agreed to instead of playing
+	OutcomeIntentionalDraw Outcome = "intentional_draw"
+	// OutcomeConcession is a game the player conceded
+	OutcomeConcession Outcome = "concession"
+	// OutcomePenalty is a game loss the player received from a judge's penalty
+	OutcomePenalty Outcome = "penalty"
+)
+
+// Outcomes are all outcomes, in the order they are offered to users
+var Outcomes = []Outcome{
+	OutcomeWin,
+	OutcomeLoss,
+	OutcomeDraw,
+	OutcomeIntentionalDraw,
+	OutcomeConcession,
+	OutcomePenalty,
+}
+
+// SQL conditions on player_games or game_results matching the outcomes that count as
+// a win, a loss or a draw
+const (
+	winOutcomeFilter  = `outcome = '` + string(OutcomeWin) + `'`
+	lossOutcomeFilter = `outcome IN ('` + string(OutcomeLoss) + `', '` + string(OutcomeConcession) + `', '` + string(OutcomePenalty) + `')`
+	drawOutcomeFilter = `outcome IN ('` + string(OutcomeDraw) + `', '` + string(OutcomeIntentionalDraw) + `')`
+)
+
+// outcomeAliases are the words accepted for each outcome when typed by hand, as in
+// /record-games
+var outcomeAliases = map[string]Outcome{
+	"win":              OutcomeWin,
+	"won":              OutcomeWin,
+	"loss":             OutcomeLoss,
+	"lost":             OutcomeLoss,
+	"lose":             OutcomeLoss,
+	"draw":             OutcomeDraw,
+	"id":               OutcomeIntentionalDraw,
+	"intentional draw": OutcomeIntentionalDraw,
+	"intentional_draw": OutcomeIntentionalDraw,
+	"concede":          OutcomeConcession,
+	"conceded":         OutcomeConcession,
+	"concession":       OutcomeConcession,
+	"penalty":          OutcomePenalty,
+	"game loss":        OutcomePenalty,
+}
+
+// ParseOutcome reads an outcome typed by hand, ignoring case
+func ParseOutcome(s string) (Outcome, bool) {
+	outcome, ok := outcomeAliases[strings.ToLower(strings.TrimSpace(s))]
+	return outcome, ok
+}
+
+// Valid reports whether o is one of the known outcomes
+func (o Outcome) Valid() bool {
+	for _, outcome := range Outcomes {
+		if o == outcome {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is how the outcome counts toward a record: "win", "loss" or "draw"
+func (o Outcome) Result() string {
+	switch o {
+	case OutcomeWin:
+		return "win"
+	case OutcomeDraw, OutcomeIntentionalDraw:
+		return "draw"
+	default:
+		return "loss"
+	}
+}
+
+// Label is the outcome as shown to users
+func (o Outcome) Label() string {
+	switch o {
+	case OutcomeWin:
+		return "Win"
+	case OutcomeLoss:
+		return "Loss"
+	case OutcomeDraw:
+		return "Draw"
+	case OutcomeIntentionalDraw:
+		return "Intentional draw"
+	case OutcomeConcession:
+		return "Conceded"
+	case OutcomePenalty:
+		return "Game loss (penalty)"
+	}
+	return string(o)
+}
+
+// Emoji marks the outcome's result in Discord messages
+func (o Outcome) Emoji() string {
+	switch o.Result() {
+	case "win":
+		return "✅"
+	case "draw":
+		return "🤝"
+	default:
+		return "❌"
+	}
+}
+
+// Mirrored is the outcome from the opponent player's side of the game. Wins and
+// losses swap and draws stay draws; the opponent of a player who conceded or took a
+// penalty loss gets a plain win.
+func (o Outcome) Mirrored() Outcome {
+	switch o.Result() {
+	case "win":
+		return OutcomeLoss
+	case "draw":
+		return o
+	default:
+		return OutcomeWin
+	}
+}
+
+// mirroredOutcomeSQL is a SQL expression turning a game_results outcome into the
+// opponent player's, as Mirrored does
+func mirroredOutcomeSQL() string {
+	var b strings.Builder
+	b.WriteString("CASE outcome")
+	for _, outcome := range Outcomes {
+		fmt.Fprintf(&b, " WHEN '%s' THEN '%s'", outcome, outcome.Mirrored())
+	}
+	b.WriteString(" END")
+	return b.String()
+}
+
+// ratingScore is the outcome's Glicko-2 score, and false if it isn't rated
+func (o Outcome) ratingScore() (float64, bool) {
+	switch o {
+	case OutcomeWin:
+		return 1, true
+	case OutcomeLoss, OutcomeConcession:
+		return 0, true
+	case OutcomeDraw:
+		return 0.5, true
+	}
+	return 0, false
+}
+
+// outcomeChoices offers the outcomes as command option choices
+func outcomeChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(Outcomes))
+	for _, outcome := range Outcomes {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: outcome.Label(), Value: string(outcome)})
+	}
+	return choices
+}
+
+// formatRecord formats a win-loss record, adding draws only when there are any
+func formatRecord(wins, losses, draws int) string {
+	record := fmt.Sprintf("%d-%d", wins, losses)
+	if draws > 0 {
+		record += fmt.Sprintf("-%d", draws)
+	}
+	return record
+}
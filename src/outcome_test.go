@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOutcome(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   Outcome
+		wantOK bool
+	}{
+		{"win", OutcomeWin, true},
+		{"  Won ", OutcomeWin, true},
+		{"LOST", OutcomeLoss, true},
+		{"lose", OutcomeLoss, true},
+		{"draw", OutcomeDraw, true},
+		{"ID", OutcomeIntentionalDraw, true},
+		{"intentional draw", OutcomeIntentionalDraw, true},
+		{"intentional_draw", OutcomeIntentionalDraw, true},
+		{"Conceded", OutcomeConcession, true},
+		{"game loss", OutcomePenalty, true},
+		{"", "", false},
+		{"tie", "", false},
+		{"w", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParseOutcome(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParseOutcome(%q) = %q, %v; want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestOutcomeRatingScore(t *testing.T) {
+	tests := []struct {
+		outcome   Outcome
+		wantScore float64
+		wantRated bool
+	}{
+		{OutcomeWin, 1, true},
+		{OutcomeLoss, 0, true},
+		{OutcomeConcession, 0, true},
+		{OutcomeDraw, 0.5, true},
+		{OutcomeIntentionalDraw, 0, false},
+		{OutcomePenalty, 0, false},
+		{Outcome("unknown"), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.outcome), func(t *testing.T) {
+			score, rated := tt.outcome.ratingScore()
+			if score != tt.wantScore || rated != tt.wantRated {
+				t.Errorf("ratingScore() = %v, %v; want %v, %v", score, rated, tt.wantScore, tt.wantRated)
+			}
+		})
+	}
+}
+
+func TestOutcomeMirrored(t *testing.T) {
+	tests := []struct {
+		outcome Outcome
+		want    Outcome
+	}{
+		{OutcomeWin, OutcomeLoss},
+		{OutcomeLoss, OutcomeWin},
+		{OutcomeConcession, OutcomeWin},
+		{OutcomePenalty, OutcomeWin},
+		{OutcomeDraw, OutcomeDraw},
+		{OutcomeIntentionalDraw, OutcomeIntentionalDraw},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.outcome), func(t *testing.T) {
+			if got := tt.outcome.Mirrored(); got != tt.want {
+				t.Errorf("Mirrored() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirroredOutcomeSQLCoversEveryOutcome(t *testing.T) {
+	sql := mirroredOutcomeSQL()
+	for _, outcome := range Outcomes {
+		when := "WHEN '" + string(outcome) + "' THEN '" + string(outcome.Mirrored()) + "'"
+		if !strings.Contains(sql, when) {
+			t.Errorf("mirroredOutcomeSQL() = %q, missing %q", sql, when)
+		}
+	}
+}
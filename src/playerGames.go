@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// playerGameComponentPrefix prefixes the custom IDs of the confirm/dispute buttons
+// attached to games recorded against another server member
+const playerGameComponentPrefix = "player-game"
+
+// recordPlayerGame records a game against another server member as pending and asks
+// the opponent player to confirm or dispute it
+func recordPlayerGame(ctx context.Context, c *commandContext, user *User, options *recordGameOptions) error {
+	opponentPlayer := options.OpponentPlayer
+	if c.GuildID() == "" {
+		return userError("❌ Games against another player can only be recorded in a server, where they can confirm them.")
+	}
+	if opponentPlayer.ID == user.DiscordID {
+		return userError("❌ You can't record a game against yourself.")
+	}
+	if opponentPlayer.Bot {
+		return userError("❌ You can't record a game against a bot.")
+	}
+
+	opponentUser, err := GetOrCreateUser(ctx, opponentPlayer.ID, opponentPlayer.Username, opponentPlayer.Discriminator)
+	if err != nil {
+		return commandFailed("❌ Failed to record game. Please try again later.", fmt.Errorf("failed to get or create opponent user: %w", err))
+	}
+
+	gameResult, err := CreatePlayerGameResult(ctx, user.ID, opponentUser.ID, c.GuildID(), options.Leader, options.Opponent, options.Category, options.WentFirst, options.Outcome, options.details())
+	if err != nil {
+		return commandFailed("❌ Failed to record game. Please try again later.", fmt.Errorf("failed to create player game result: %w", err))
+	}
+
+	c.Send(&discordgo.WebhookParams{
+		Content:    formatPlayerGameMessage(gameResult, user.DiscordID, opponentUser.DiscordID),
+		Components: playerGameButtons(gameResult.ID),
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Users: []string{opponentUser.DiscordID},
+		},
+	})
+
+	c.Logger().Info("Pending player game recorded", "username", user.Username, "game_id", gameResult.ID, "opponent_username", opponentUser.Username)
+	return nil
+}
+
+// playerGameButtons are the confirm and dispute buttons for a pending player game
+func playerGameButtons(gameID int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Confirm",
+					Style:    discordgo.SuccessButton,
+					CustomID: fmt.Sprintf("%s:%s:%d", playerGameComponentPrefix, GameStatusConfirmed, gameID),
+				},
+				discordgo.Button{
+					Label:    "Dispute",
+					Style:    discordgo.DangerButton,
+					CustomID: fmt.Sprintf("%s:%s:%d", playerGameComponentPrefix, GameStatusDisputed, gameID),
+				},
+			},
+		},
+	}
+}
+
+// formatPlayerGameMessage describes a game between two server members from the
+// reporting player's perspective, headed by its current status
+func formatPlayerGameMessage(gameResult *GameResult, reporterID, opponentID string) string {
+	turnText := "second"
+	if gameResult.WentFirst {
+		turnText = "first"
+	}
+
+	var header, footer string
+	switch gameResult.Status {
+	case GameStatusConfirmed:
+		header = "✅ **Game Confirmed!**"
+		footer = fmt.Sprintf("<@%s> confirmed this result.", opponentID)
+	case GameStatusDisputed:
+		header = "⚠️ **Game Disputed**"
+		footer = fmt.Sprintf("<@%s> disputed this result. It won't count toward head-to-head stats.", opponentID)
+	default:
+		header = "⏳ **Game Pending Confirmation**"
+		footer = fmt.Sprintf("<@%s>, please confirm or dispute this result.", opponentID)
+	}
+
+	return fmt.Sprintf("%s\n🎮 <@%s> (**%s**) vs <@%s> (**%s**)\n📂 Category: **%s**\n🎯 <@%s> went **%s** • %s **%s**%s\n\n%s",
+		header, reporterID, gameResult.Leader, opponentID, gameResult.Opponent, gameResult.Category,
+		reporterID, turnText, gameResult.Outcome.Emoji(), gameResult.Outcome.Label(), gameDetailsLine(gameResult.GameDetails), footer)
+}
+
+// playerGameComponent handles the confirm and dispute buttons on a pending player game
+func playerGameComponent(ctx context.Context, discord *discordgo.Session, i *discordgo.InteractionCreate) {
+	interactionLogger(i).Info("Player game component executed")
+
+	// Custom IDs look like player-game:<status>:<game id>
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if len(parts) != 3 {
+		respondEphemeralError(ctx, discord, i, "❌ Unknown action.")
+		return
+	}
+	status := parts[1]
+	gameID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		respondEphemeralError(ctx, discord, i, "❌ Unknown action.")
+		return
+	}
+
+	gameResult, err := GetGameResultByID(ctx, gameID)
+	if err != nil {
+		interactionLogger(i).Error("Failed to get game result", "error", err)
+		respondEphemeralError(ctx, discord, i, "❌ This game no longer exists.")
+		return
+	}
+
+	// Only the opponent player may resolve the game
+	user, err := GetUserByDiscordID(ctx, interactionUser(i).ID)
+	if err != nil || gameResult.OpponentUserID == nil || *gameResult.OpponentUserID != user.ID {
+		respondEphemeralError(ctx, discord, i, "❌ Only the opponent player can confirm or dispute this game.")
+		return
+	}
+
+	gameResult, err = ResolvePlayerGameResult(ctx, gameID, user.ID, status)
+	if err != nil {
+		interactionLogger(i).Error("Failed to resolve player game result", "error", err)
+		respondEphemeralError(ctx, discord, i, "❌ This game has already been confirmed or disputed.")
+		return
+	}
+
+	if gameResult.Status == GameStatusConfirmed {
+		err = UpdateRatingsForGame(ctx, gameResult)
+		if err != nil {
+			// The game itself is confirmed; /rating-recompute will pick it up later
+			interactionLogger(i).Error("Failed to update ratings", "game_id", gameResult.ID, "error", err)
+		}
+	}
+
+	reporter, err := GetUserByID(ctx, gameResult.UserID)
+	if err != nil {
+		interactionLogger(i).Error("Failed to get reporting user", "error", err)
+		respondEphemeralError(ctx, discord, i, "❌ Failed to update the game. Please try again later.")
+		return
+	}
+
+	err = discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:         formatPlayerGameMessage(gameResult, reporter.DiscordID, user.DiscordID),
+			Components:      []discordgo.MessageComponent{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		interactionLogger(i).Error("Failed to update player game message", "error", err)
+		return
+	}
+
+	interactionLogger(i).Info("Player game resolved", "username", user.Username, "game_id", gameID, "status", status)
+}
+
+type headToHeadOptions struct {
+	Player *discordgo.User `option:"player" description:"The server member to compare against" required:"true"`
+}
+
+var headToHeadCommand = &slashCommand[headToHeadOptions]{
+	Name:             "head-to-head",
+	Description:      "Show your confirmed record against another server member",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runHeadToHead,
+}
+
+func runHeadToHead(ctx context.Context, c *commandContext, options *headToHeadOptions) error {
+	opponentPlayer := options.Player
+	if opponentPlayer == nil || opponentPlayer.ID == c.Invoker().ID {
+		return userError("❌ Pick another server member to compare against.")
+	}
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed("❌ Failed to load head-to-head. Please try again later.", err)
+	}
+
+	record := &HeadToHeadRecord{}
+	opponentUser, err := GetUserByDiscordID(ctx, opponentPlayer.ID)
+	if err == nil {
+		record, err = GetHeadToHead(ctx, user.ID, opponentUser.ID)
+	} else if errors.Is(err, ErrUserNotFound) {
+		err = nil
+	}
+	if err != nil {
+		return commandFailed("❌ Failed to load head-to-head. Please try again later.", fmt.Errorf("failed to get head-to-head: %w", err))
+	}
+
+	if len(record.Matchups) == 0 {
+		c.Replyf("🤝 No confirmed games against <@%s> yet.\nRecord one with `/record-game` and the `opponent_player` option.", opponentPlayer.ID)
+		return nil
+	}
+
+	var lines []string
+	for _, matchup := range record.Matchups {
+		lines = append(lines, fmt.Sprintf("• **%s** vs **%s**: %s", matchup.Leader, matchup.Opponent, formatRecord(matchup.Wins, matchup.Losses, matchup.Draws)))
+	}
+	c.Replyf("🤝 **Head-to-Head vs <@%s>**\n📊 Record: **%s**\n\n%s",
+		opponentPlayer.ID, formatRecord(record.Wins, record.Losses, record.Draws), strings.Join(lines, "\n"))
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// forgetMeComponentPrefix prefixes the custom IDs of the /forget-me confirmation buttons
+const forgetMeComponentPrefix = "forget-me"
+
+var myDataCommand = &slashCommand[noOptions]{
+	Name:             "my-data",
+	Description:      "Get a copy of everything the bot stores about you, sent by DM",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Ephemeral:        true,
+	Run:              runMyData,
+}
+
+func runMyData(ctx context.Context, c *commandContext, options *noOptions) error {
+	const failure = "❌ Failed to export your data. Please try again later."
+
+	// Look the user up rather than using c.User, which would create them
+	user, err := GetUserByDiscordID(ctx, c.Invoker().ID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			c.Reply("📭 The bot doesn't store anything about you.")
+			return nil
+		}
+		return commandFailed(failure, err)
+	}
+
+	export, err := ExportUserData(ctx, user)
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to export user data: %w", err))
+	}
+
+	archive, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to encode user data: %w", err))
+	}
+
+	channel, err := c.Discord.UserChannelCreate(user.DiscordID)
+	if err != nil {
+		return commandFailed("❌ I couldn't DM you. Allow direct messages from this server's members and try again.", fmt.Errorf("failed to open dm channel: %w", err))
+	}
+
+	_, err = c.Discord.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("📦 **Your data** as of %s UTC: your profile, %d games, %d ratings, %d game groups you created and your API token and dashboard logins (without the secret tokens). Use `/forget-me` to delete it all.",
+			export.ExportedAt.Format("2006-01-02 15:04"), len(export.Games), len(export.Ratings), len(export.GameGroups)),
+		Files: []*discordgo.File{
+			{
+				Name:        "my-data.json",
+				ContentType: "application/json",
+				Reader:      bytes.NewReader(archive),
+			},
+		},
+	})
+	if err != nil {
+		return commandFailed("❌ I couldn't DM you. Allow direct messages from this server's members and try again.", fmt.Errorf("failed to send data export: %w", err))
+	}
+
+	c.Reply("📬 Sent your data to your DMs.")
+
+	c.Logger().Info("User data exported", "games", len(export.Games))
+	return nil
+}
+
+var forgetMeCommand = &slashCommand[noOptions]{
+	Name:             "forget-me",
+	Description:      "Delete everything the bot stores about you",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Ephemeral:        true,
+	Run:              runForgetMe,
+}
+
+func runForgetMe(ctx context.Context, c *commandContext, options *noOptions) error {
+	_, err := GetUserByDiscordID(ctx, c.Invoker().ID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			c.Reply("📭 The bot doesn't store anything about you.")
+			return nil
+		}
+		return commandFailed("❌ Failed to look up your data. Please try again later.", err)
+	}
+
+	// The buttons carry the invoker's ID so nobody else can press them
+	c.Send(&discordgo.WebhookParams{
+		Content: "⚠️ **Delete all your data?**\nThis permanently deletes your profile, every game you recorded, your ratings, your API token and your dashboard logins. " +
+			"Games other members recorded against you are kept for them, without you. This can't be undone, so consider `/my-data` first.",
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Delete everything",
+						Style:    discordgo.DangerButton,
+						CustomID: fmt.Sprintf("%s:confirm:%s", forgetMeComponentPrefix, c.Invoker().ID),
+					},
+					discordgo.Button{
+						Label:    "Cancel",
+						Style:    discordgo.SecondaryButton,
+						CustomID: fmt.Sprintf("%s:cancel:%s", forgetMeComponentPrefix, c.Invoker().ID),
+					},
+				},
+			},
+		},
+	})
+	return nil
+}
+
+// forgetMeComponent handles the buttons on the /forget-me confirmation
+func forgetMeComponent(ctx context.Context, discord *discordgo.Session, i *discordgo.InteractionCreate) {
+	interactionLogger(i).Info("Forget me component executed")
+
+	// Custom IDs look like forget-me:<confirm|cancel>:<discord id>
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if len(parts) != 3 || parts[2] != interactionUser(i).ID {
+		respondEphemeralError(ctx, discord, i, "❌ Unknown action.")
+		return
+	}
+
+	content := "👍 Nothing was deleted."
+	if parts[1] == "confirm" {
+		user, err := GetUserByDiscordID(ctx, parts[2])
+		if err != nil {
+			if !errors.Is(err, ErrUserNotFound) {
+				interactionLogger(i).Error("Failed to get user", "error", err)
+				respondEphemeralError(ctx, discord, i, "❌ Failed to delete your data. Please try again later.")
+				return
+			}
+			content = "🗑️ Your data was already deleted."
+		} else {
+			deletion, err := DeleteUserData(ctx, user.ID, i.GuildID)
+			if err != nil {
+				interactionLogger(i).Error("Failed to delete user data", "error", err)
+				respondEphemeralError(ctx, discord, i, "❌ Failed to delete your data. Please try again later.")
+				return
+			}
+			content = fmt.Sprintf("🗑️ **Your data has been deleted**: %d games and %d ratings. Using the bot again starts a fresh profile.",
+				deletion.Games, deletion.Ratings)
+			interactionLogger(i).Info("User data deleted", "games", deletion.Games, "detached_games", deletion.DetachedGames)
+		}
+	}
+
+	err := discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		interactionLogger(i).Error("Failed to update forget me message", "error", err)
+	}
+}
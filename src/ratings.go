@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ratingLeaderboardSize is the number of players shown by /rating-leaderboard
+const ratingLeaderboardSize = 10
+
+// formatRating renders a rating as "1523 ±87"
+func formatRating(rating GlickoRating) string {
+	return fmt.Sprintf("%d ±%d", int(math.Round(rating.Rating)), int(math.Round(rating.RD)))
+}
+
+type ratingOptions struct {
+	Player   *discordgo.User `option:"player" description:"Whose rating to show (defaults to you; only you outside a server)"`
+	Category string          `option:"category" description:"Only show the rating for this category" autocomplete:"category"`
+}
+
+var ratingCommand = &slashCommand[ratingOptions]{
+	Name:             "rating",
+	Description:      "Show this server's Glicko-2 ratings from confirmed games against its members",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runRating,
+}
+
+func runRating(ctx context.Context, c *commandContext, options *ratingOptions) error {
+	category := NormalizeCategory(options.Category)
+	player := options.Player
+	if player == nil {
+		player = c.Invoker()
+	}
+	// Outside a server only your own ratings are shown, since there's no server to scope
+	// someone else's to
+	guildID := c.GuildID()
+	if guildID == "" && player.ID != c.Invoker().ID {
+		return userError("❌ Other players' ratings can only be viewed in a server you share.")
+	}
+
+	var ratings []*PlayerRating
+	user, err := GetUserByDiscordID(ctx, player.ID)
+	if err == nil {
+		ratings, err = GetUserRatings(ctx, user.ID, guildID)
+	} else if errors.Is(err, ErrUserNotFound) {
+		err = nil
+	}
+	if err != nil {
+		return commandFailed("❌ Failed to load ratings. Please try again later.", fmt.Errorf("failed to get user ratings: %w", err))
+	}
+
+	var lines []string
+	for _, rating := range ratings {
+		if category != "" && rating.Category != category {
+			continue
+		}
+		line := fmt.Sprintf("• **%s**: %s (%d games, %s)",
+			rating.Category, formatRating(rating.GlickoRating), rating.Games, formatRecord(rating.Wins, rating.Losses, rating.Draws()))
+		if guildID == "" {
+			line += " in " + ratingGuildName(c.Discord, rating.GuildID)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		c.Replyf("📈 <@%s> has no rating yet. Ratings come from confirmed games recorded with the `opponent_player` option.\nNew players start at %s.",
+			player.ID, formatRating(NewGlickoRating()))
+		return nil
+	}
+	c.Replyf("📈 **Ratings for <@%s>**\n\n%s", player.ID, strings.Join(lines, "\n"))
+	return nil
+}
+
+type ratingLeaderboardOptions struct {
+	Category string `option:"category" description:"Rating category" required:"true" autocomplete:"category"`
+}
+
+var ratingLeaderboardCommand = &slashCommand[ratingLeaderboardOptions]{
+	Name:             "rating-leaderboard",
+	Description:      "Show the highest rated server members in a category",
+	Contexts:         guildOnlyContexts,
+	IntegrationTypes: guildInstallTypes,
+	Run:              runRatingLeaderboard,
+}
+
+func runRatingLeaderboard(ctx context.Context, c *commandContext, options *ratingLeaderboardOptions) error {
+	category := NormalizeCategory(options.Category)
+
+	ratings, err := GetRatingLeaderboard(ctx, c.GuildID(), category, ratingLeaderboardSize)
+	if err != nil {
+		return commandFailed("❌ Failed to load the leaderboard. Please try again later.", fmt.Errorf("failed to get rating leaderboard: %w", err))
+	}
+
+	if len(ratings) == 0 {
+		c.Replyf("🏆 No rated **%s** games yet.", category)
+		return nil
+	}
+
+	var lines []string
+	for rank, rating := range ratings {
+		lines = append(lines, fmt.Sprintf("%d. <@%s> — **%s** (%d games)",
+			rank+1, rating.DiscordID, formatRating(rating.GlickoRating), rating.Games))
+	}
+	c.Replyf("🏆 **%s Rating Leaderboard**\n\n%s", category, strings.Join(lines, "\n"))
+	return nil
+}
+
+var ratingRecomputeCommand = &slashCommand[noOptions]{
+	Name:             "rating-recompute",
+	Description:      "Rebuild this server's ratings from its full game history",
+	Permissions:      &adminPermission,
+	Contexts:         guildOnlyContexts,
+	IntegrationTypes: guildInstallTypes,
+	Ephemeral:        true,
+	Run:              runRatingRecompute,
+}
+
+func runRatingRecompute(ctx context.Context, c *commandContext, options *noOptions) error {
+	gamesReplayed, err := RecomputeRatings(ctx, c.GuildID())
+	if err != nil {
+		return commandFailed("❌ Failed to recompute ratings. Please try again later.", fmt.Errorf("failed to recompute ratings: %w", err))
+	}
+
+	c.Replyf("✅ Recomputed this server's ratings from **%d** confirmed games.", gamesReplayed)
+
+	c.Logger().Info("Ratings recomputed", "guild_id", c.GuildID(), "username", c.Invoker().Username, "games_replayed", gamesReplayed)
+	return nil
+}
+
+// ratingGuildName names the server a rating belongs to, falling back to its ID when the
+// bot no longer has it cached
+func ratingGuildName(discord *discordgo.Session, guildID string) string {
+	if guild, err := discord.State.Guild(guildID); err == nil && guild.Name != "" {
+		return "**" + guild.Name + "**"
+	}
+	return "server `" + guildID + "`"
+}
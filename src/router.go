@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// genericErrorMessage is sent when a handler fails without replying, so the user is
+// never left looking at "thinking…"
+const genericErrorMessage = "❌ Something went wrong while handling that. Please try again later."
+
+// interactionRouter dispatches interactions to handlers by interaction type. Commands
+// and autocomplete requests are keyed by command name; message components and modal
+// submissions by the first segment of their custom ID.
+type interactionRouter struct {
+	commands     map[string]interactionHandler
+	autocomplete map[string]interactionHandler
+	components   map[string]interactionHandler
+	modals       map[string]interactionHandler
+}
+
+// route finds the handler for an interaction
+func (r *interactionRouter) route(i *discordgo.InteractionCreate) (interactionHandler, bool) {
+	var handlers map[string]interactionHandler
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		handlers = r.commands
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		handlers = r.autocomplete
+	case discordgo.InteractionMessageComponent:
+		handlers = r.components
+	case discordgo.InteractionModalSubmit:
+		handlers = r.modals
+	}
+
+	h, ok := handlers[interactionName(i)]
+	return h, ok
+}
+
+// handle is registered with the Discord session as the single InteractionCreate handler
+func (r *interactionRouter) handle(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Discord sends a ping to verify the endpoint; gateway bots never see one, but
+	// there is nothing to route either way
+	if i.Type == discordgo.InteractionPing {
+		return
+	}
+
+	// Once shutdown starts, new interactions are turned away while in-flight ones finish
+	if !inFlightInteractions.begin() {
+		if i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+			respondShuttingDown(s, i)
+		}
+		return
+	}
+	defer inFlightInteractions.done()
+
+	name := interactionName(i)
+	h, ok := r.route(i)
+	if !ok {
+		interactionLogger(i).Warn("No handler for interaction", "type", i.Type.String())
+		if i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+			respondEphemeral(s, i, "❌ This action isn't available anymore.")
+		}
+		return
+	}
+
+	// Bound the handler's database work well inside Discord's followup window
+	ctx, cancel := context.WithTimeout(context.Background(), AppConfig.InteractionTimeout())
+	defer cancel()
+	ctx = withAuditSource(ctx, i)
+
+	observeInteraction(name, i, func() {
+		defer recoverInteraction(s, i)
+		h(ctx, s, i)
+	})
+}
+
+// recoverInteraction must be deferred around a handler call. It stops a handler panic
+// from taking down the bot, logs it with the stack trace and tells the user.
+func recoverInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	interactionLogger(i).Error("Interaction handler panicked",
+		"panic", fmt.Sprint(recovered),
+		"stack", string(debug.Stack()),
+	)
+	markInteractionOutcome(i, "panic")
+
+	// Autocomplete requests have nowhere to show a message
+	if i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		sendGenericError(s, i)
+	}
+}
+
+// sendGenericError tells the user something went wrong, whether or not the handler
+// had already acknowledged the interaction
+func sendGenericError(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: genericErrorMessage,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err == nil {
+		return
+	}
+
+	// The interaction was already deferred or answered, so follow up instead
+	_, err = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: genericErrorMessage,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		interactionLogger(i).Error("Failed to send generic error reply", "error", err)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// formatPracticeMinutes formats practice time as hours and minutes
+func formatPracticeMinutes(minutes int) string {
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+// sessionGameNote tells the user a game they just recorded was added to their open
+// practice session
+func sessionGameNote(gameResult *GameResult) string {
+	if gameResult.SessionID == nil {
+		return ""
+	}
+	return "\n⏱️ Added to your practice session."
+}
+
+// weekPracticeLine describes the user's practice time so far this week, or "" if they
+// haven't run a session
+func weekPracticeLine(ctx context.Context, user *User) (string, error) {
+	now := time.Now().In(userLocation(user))
+	practice, err := GetUserPracticeTime(ctx, user.ID, startOfWeek(now), time.Time{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get practice time: %w", err)
+	}
+	if practice.Sessions == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("⏱️ This week: **%s** practiced over **%d** session(s)", formatPracticeMinutes(practice.Minutes), practice.Sessions), nil
+}
+
+var sessionCommand = &commandGroup{
+	Name:             "session",
+	Description:      "Time a block of practice",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Subcommands: []subcommandSpec{
+		sessionStartCommand,
+		sessionStopCommand,
+	},
+}
+
+var sessionStartCommand = &slashCommand[noOptions]{
+	Name:        "start",
+	Description: "Start a practice session; games you record are added to it",
+	Run:         runSessionStart,
+}
+
+func runSessionStart(ctx context.Context, c *commandContext, options *noOptions) error {
+	const failure = "❌ Failed to start the session. Please try again later."
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	session, started, err := StartPracticeSession(ctx, user.ID, c.GuildID())
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to start practice session: %w", err))
+	}
+	if !started {
+		return userError("⏱️ You already have a session running since <t:%d:R>. End it with `/session stop`.", session.StartedAt.Unix())
+	}
+
+	c.Replyf("▶️ **Practice session started** <t:%d:t>. Games you record are added to it until you run `/session stop`, and so are games members record against you once you confirm them. Sessions end on their own after %d hours.",
+		session.StartedAt.Unix(), int(MaxSessionLength.Hours()))
+
+	c.Logger().Info("Practice session started", "session_id", session.ID)
+	return nil
+}
+
+var sessionStopCommand = &slashCommand[noOptions]{
+	Name:        "stop",
+	Description: "End your practice session and see how it went",
+	Run:         runSessionStop,
+}
+
+func runSessionStop(ctx context.Context, c *commandContext, options *noOptions) error {
+	const failure = "❌ Failed to stop the session. Please try again later."
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	session, err := StopPracticeSession(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, ErrNoOpenSession) {
+			return userError("⏱️ You don't have a session running. Start one with `/session start`.")
+		}
+		return commandFailed(failure, fmt.Errorf("failed to stop practice session: %w", err))
+	}
+
+	report, err := GetPracticeSessionReport(ctx, session.ID)
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to get session report: %w", err))
+	}
+
+	content := fmt.Sprintf("⏹️ **Practice session ended** • ⏱️ **%s**", formatPracticeMinutes(int(session.Duration().Minutes())))
+	if session.Duration() >= MaxSessionLength {
+		content += fmt.Sprintf(" (it ran out after %d hours)", int(MaxSessionLength.Hours()))
+	}
+	if report.Games == 0 {
+		content += "\n🎮 No games recorded during this session. Games members recorded against you count once you confirm them."
+	} else {
+		content += fmt.Sprintf("\n🎮 Games: **%d** • Record: **%s** • Win rate: **%.1f%%**\n🃏 Leaders: %s",
+			report.Games, formatRecord(report.Wins, report.Losses, report.Draws), winRatePercent(report.Wins, report.Losses),
+			strings.Join(report.Leaders, ", "))
+	}
+
+	week, err := weekPracticeLine(ctx, user)
+	if err != nil {
+		c.Logger().Error("Failed to get weekly practice time", "error", err)
+	} else if week != "" {
+		content += "\n" + week
+	}
+
+	c.Reply(content)
+
+	c.Logger().Info("Practice session stopped", "session_id", session.ID, "games", report.Games)
+	return nil
+}
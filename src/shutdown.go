@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// interactionTracker counts in-flight interaction handlers so shutdown can stop
+// accepting new interactions and wait for the running ones to send their followups
+type interactionTracker struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+}
+
+// inFlightInteractions tracks the handlers started by the interaction dispatcher
+var inFlightInteractions = &interactionTracker{}
+
+// begin registers a new interaction, returning false once shutdown has started
+func (t *interactionTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining {
+		return false
+	}
+	t.inFlight.Add(1)
+	return true
+}
+
+// done marks an interaction started with begin as finished
+func (t *interactionTracker) done() {
+	t.inFlight.Done()
+}
+
+// drain stops new interactions from starting and waits for in-flight ones until
+// the context is done. It reports whether every handler finished.
+func (t *interactionTracker) drain(ctx context.Context) bool {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		t.inFlight.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// respondShuttingDown tells the user the bot is restarting instead of handling the interaction
+func respondShuttingDown(discord *discordgo.Session, i *discordgo.InteractionCreate) {
+	err := discord.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "🔄 The bot is restarting. Please try again in a moment.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		interactionLogger(i).Error("Failed to send shutdown response", "error", err)
+	}
+}
+
+// shutdown stops the bot in order: stop taking interactions and wait for in-flight
+// ones, stop the HTTP server, optionally deregister commands, then close the Discord
+// session and the database. Every step is best effort so one failure can't block the rest.
+func shutdown(discord *discordgo.Session, httpServer *http.Server, registeredCommands []*discordgo.ApplicationCommand) {
+	timeout := AppConfig.ShutdownTimeout()
+	slog.Info("Shutting down, waiting for in-flight interactions", "timeout", timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if inFlightInteractions.drain(ctx) {
+		slog.Info("All in-flight interactions finished")
+	} else {
+		slog.Warn("Timed out waiting for in-flight interactions")
+	}
+
+	httpCtx, httpCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer httpCancel()
+	err := httpServer.Shutdown(httpCtx)
+	if err != nil {
+		slog.Error("Failed to shut down HTTP server", "error", err)
+	}
+
+	if AppConfig.RemoveCommandsOnExit {
+		removeCommands(registeredCommands, discord)
+	}
+
+	err = discord.Close()
+	if err != nil {
+		slog.Error("Failed to close Discord session", "error", err)
+	}
+
+	err = CloseDB()
+	if err != nil {
+		slog.Error("Failed to close database", "error", err)
+	}
+
+	slog.Info("Shutdown complete")
+}
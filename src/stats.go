@@ -0,0 +1,668 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// countedGamesFilter restricts player_games to games that count toward stats:
+// everything except player games that are still pending or were disputed
+const countedGamesFilter = `status IN ('` + GameStatusRecorded + `', '` + GameStatusConfirmed + `')`
+
+// StatsFilter narrows the games used by the aggregation queries. Zero values
+// mean no restriction.
+type StatsFilter struct {
+	Category string
+	// From is inclusive and To is exclusive
+	From time.Time
+	To   time.Time
+}
+
+// statsFilterClause applies a StatsFilter to player_games. It expects the filter's
+// args() as query parameters $2 to $4, with $1 left for the user or guild.
+const statsFilterClause = `($2 = '' OR category = $2)
+			AND ($3::timestamptz IS NULL OR created_at >= $3)
+			AND ($4::timestamptz IS NULL OR created_at < $4)`
+
+// args returns the query parameters used by statsFilterClause
+func (f StatsFilter) args() []any {
+	var from, to *time.Time
+	if !f.From.IsZero() {
+		from = &f.From
+	}
+	if !f.To.IsZero() {
+		to = &f.To
+	}
+	return []any{f.Category, from, to}
+}
+
+// parseStatsFilter builds a StatsFilter from user input. Dates are YYYY-MM-DD in
+// loc and both ends are inclusive; empty values leave that side unrestricted.
+func parseStatsFilter(category, from, to string, loc *time.Location) (StatsFilter, error) {
+	filter := StatsFilter{}
+	if category != "" {
+		if !ValidateCategory(category) {
+			return filter, fmt.Errorf("invalid category")
+		}
+		filter.Category = NormalizeCategory(category)
+	}
+
+	if from != "" {
+		day, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from date")
+		}
+		filter.From = day
+	}
+
+	if to != "" {
+		day, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to date")
+		}
+		filter.To = day.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
+
+// MetaEntry is one opponent leader in a guild's meta report
+type MetaEntry struct {
+	Leader string `json:"leader"`
+	Games  int    `json:"games"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	// ThisWeek and LastWeek count games against the leader in the last 7 days
+	// and the 7 days before that, regardless of the report window
+	ThisWeek int `json:"this_week"`
+	LastWeek int `json:"last_week"`
+}
+
+// MetaReport is the opponent leader breakdown for a guild over a window of days
+type MetaReport struct {
+	Days          int          `json:"days"`
+	Category      string       `json:"category,omitempty"`
+	TotalGames    int          `json:"total_games"`
+	ThisWeekGames int          `json:"this_week_games"`
+	LastWeekGames int          `json:"last_week_games"`
+	Entries       []*MetaEntry `json:"entries"`
+}
+
+// GetGuildMeta aggregates the opponent leaders faced by a guild's members over the
+// last `days` days, optionally limited to one category. Opponent names are grouped
+// case-insensitively since they are typed in by hand. Games are read from game_results
+// rather than player_games so a confirmed game between two members counts once, from
+// the reporter's side. Games recorded before game_results had a guild_id aren't linked
+// to any guild and are left out.
+func GetGuildMeta(ctx context.Context, guildID string, days int, category string) (*MetaReport, error) {
+	query := `
+		SELECT MODE() WITHIN GROUP (ORDER BY TRIM(opponent)) AS leader,
+			COUNT(*) FILTER (WHERE created_at >= NOW() - make_interval(days => $2)) AS games,
+			COUNT(*) FILTER (WHERE ` + winOutcomeFilter + ` AND created_at >= NOW() - make_interval(days => $2)) AS wins,
+			COUNT(*) FILTER (WHERE ` + lossOutcomeFilter + ` AND created_at >= NOW() - make_interval(days => $2)) AS losses,
+			COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days') AS this_week,
+			COUNT(*) FILTER (WHERE created_at < NOW() - INTERVAL '7 days'
+				AND created_at >= NOW() - INTERVAL '14 days') AS last_week
+		FROM game_results
+		WHERE guild_id = $1
+			AND created_at >= NOW() - make_interval(days => GREATEST($2, 14))
+			AND ($3 = '' OR category = $3)
+			AND ` + countedGamesFilter + `
+		GROUP BY LOWER(TRIM(opponent))
+		ORDER BY games DESC, leader
+	`
+
+	rows, err := DB.QueryContext(ctx, query, guildID, days, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guild meta: %w", err)
+	}
+	defer rows.Close()
+
+	report := &MetaReport{Days: days, Category: category}
+	for rows.Next() {
+		entry := &MetaEntry{}
+		err = rows.Scan(&entry.Leader, &entry.Games, &entry.Wins, &entry.Losses, &entry.ThisWeek, &entry.LastWeek)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan meta entry: %w", err)
+		}
+
+		report.ThisWeekGames += entry.ThisWeek
+		report.LastWeekGames += entry.LastWeek
+		if entry.Games == 0 {
+			continue
+		}
+		report.TotalGames += entry.Games
+		report.Entries = append(report.Entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get guild meta: %w", err)
+	}
+
+	return report, nil
+}
+
+// UserSummary is a user's overall record, split by turn order
+type UserSummary struct {
+	Games        int `json:"games"`
+	Wins         int `json:"wins"`
+	Losses       int `json:"losses"`
+	Draws        int `json:"draws"`
+	FirstGames   int `json:"first_games"`
+	FirstWins    int `json:"first_wins"`
+	FirstLosses  int `json:"first_losses"`
+	SecondGames  int `json:"second_games"`
+	SecondWins   int `json:"second_wins"`
+	SecondLosses int `json:"second_losses"`
+	ConfirmedPvP int `json:"confirmed_pvp"`
+}
+
+// GetUserSummary returns a user's record for the games matching the filter
+func GetUserSummary(ctx context.Context, userID int, filter StatsFilter) (*UserSummary, error) {
+	query := `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE ` + winOutcomeFilter + `),
+			COUNT(*) FILTER (WHERE ` + lossOutcomeFilter + `),
+			COUNT(*) FILTER (WHERE ` + drawOutcomeFilter + `),
+			COUNT(*) FILTER (WHERE went_first),
+			COUNT(*) FILTER (WHERE went_first AND ` + winOutcomeFilter + `),
+			COUNT(*) FILTER (WHERE went_first AND ` + lossOutcomeFilter + `),
+			COUNT(*) FILTER (WHERE NOT went_first),
+			COUNT(*) FILTER (WHERE NOT went_first AND ` + winOutcomeFilter + `),
+			COUNT(*) FILTER (WHERE NOT went_first AND ` + lossOutcomeFilter + `),
+			COUNT(*) FILTER (WHERE status = '` + GameStatusConfirmed + `')
+		FROM player_games
+		WHERE user_id = $1 AND ` + statsFilterClause + ` AND ` + countedGamesFilter
+
+	summary := &UserSummary{}
+	err := DB.QueryRowContext(ctx, query, append([]any{userID}, filter.args()...)...).Scan(
+		&summary.Games,
+		&summary.Wins,
+		&summary.Losses,
+		&summary.Draws,
+		&summary.FirstGames,
+		&summary.FirstWins,
+		&summary.FirstLosses,
+		&summary.SecondGames,
+		&summary.SecondWins,
+		&summary.SecondLosses,
+		&summary.ConfirmedPvP,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// MatchupRecord is a user's record with one leader against one opponent leader
+type MatchupRecord struct {
+	Leader   string `json:"leader"`
+	Opponent string `json:"opponent"`
+	Games    int    `json:"games"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Draws    int    `json:"draws"`
+}
+
+// GetUserMatchups returns a user's record per leader and opponent leader, most played
+// first. Leader names are grouped case-insensitively.
+func GetUserMatchups(ctx context.Context, userID int, filter StatsFilter) ([]*MatchupRecord, error) {
+	query := `
+		SELECT MODE() WITHIN GROUP (ORDER BY TRIM(leader)) AS leader,
+			MODE() WITHIN GROUP (ORDER BY TRIM(opponent)) AS opponent,
+			COUNT(*) AS games,
+			COUNT(*) FILTER (WHERE ` + winOutcomeFilter + `) AS wins,
+			COUNT(*) FILTER (WHERE ` + lossOutcomeFilter + `) AS losses,
+			COUNT(*) FILTER (WHERE ` + drawOutcomeFilter + `) AS draws
+		FROM player_games
+		WHERE user_id = $1 AND ` + statsFilterClause + ` AND ` + countedGamesFilter + `
+		GROUP BY LOWER(TRIM(leader)), LOWER(TRIM(opponent))
+		ORDER BY games DESC, leader, opponent
+	`
+
+	rows, err := DB.QueryContext(ctx, query, append([]any{userID}, filter.args()...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user matchups: %w", err)
+	}
+	defer rows.Close()
+
+	var matchups []*MatchupRecord
+	for rows.Next() {
+		matchup := &MatchupRecord{}
+		err = rows.Scan(&matchup.Leader, &matchup.Opponent, &matchup.Games, &matchup.Wins, &matchup.Losses, &matchup.Draws)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan matchup: %w", err)
+		}
+		matchups = append(matchups, matchup)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user matchups: %w", err)
+	}
+
+	return matchups, nil
+}
+
+// DailyResult is a user's games on a single day in their timezone
+type DailyResult struct {
+	Day    time.Time `json:"day"`
+	Games  int       `json:"games"`
+	Wins   int       `json:"wins"`
+	Losses int       `json:"losses"`
+}
+
+// GetUserDailyResults returns a user's games grouped by day in the given timezone,
+// oldest first
+func GetUserDailyResults(ctx context.Context, userID int, filter StatsFilter, timezone string) ([]*DailyResult, error) {
+	query := `
+		SELECT (created_at AT TIME ZONE $5)::date AS day,
+			COUNT(*) AS games,
+			COUNT(*) FILTER (WHERE ` + winOutcomeFilter + `) AS wins,
+			COUNT(*) FILTER (WHERE ` + lossOutcomeFilter + `) AS losses
+		FROM player_games
+		WHERE user_id = $1 AND ` + statsFilterClause + ` AND ` + countedGamesFilter + `
+		GROUP BY day
+		ORDER BY day
+	`
+
+	args := append([]any{userID}, filter.args()...)
+	rows, err := DB.QueryContext(ctx, query, append(args, timezone)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*DailyResult
+	for rows.Next() {
+		result := &DailyResult{}
+		err = rows.Scan(&result.Day, &result.Games, &result.Wins, &result.Losses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan daily result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get daily results: %w", err)
+	}
+
+	return results, nil
+}
+
+// Streak describes a user's run of consecutive practice days
+type Streak struct {
+	Current      int       `json:"current"`
+	Longest      int       `json:"longest"`
+	PracticeDays int       `json:"practice_days"`
+	LastPlayed   time.Time `json:"last_played"`
+}
+
+// calculateStreak works out streaks from daily results sorted oldest first. The
+// current streak is still alive if the user played today or yesterday.
+func calculateStreak(days []*DailyResult, today time.Time) Streak {
+	streak := Streak{PracticeDays: len(days)}
+	if len(days) == 0 {
+		return streak
+	}
+
+	run := 0
+	var previous time.Time
+	for _, day := range days {
+		if run > 0 && sameDay(day.Day, previous.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		streak.Longest = max(streak.Longest, run)
+		previous = day.Day
+	}
+
+	streak.LastPlayed = previous
+	if sameDay(previous, today) || sameDay(previous, today.AddDate(0, 0, -1)) {
+		streak.Current = run
+	}
+
+	return streak
+}
+
+// addSessionDays adds a zero-game day for each practice session day without recorded
+// games, so practicing without recording still counts toward a streak. Both lists are
+// sorted oldest first and so is the result.
+func addSessionDays(days []*DailyResult, sessionDays []time.Time) []*DailyResult {
+	if len(sessionDays) == 0 {
+		return days
+	}
+
+	merged := make([]*DailyResult, 0, len(days)+len(sessionDays))
+	idx := 0
+	for _, sessionDay := range sessionDays {
+		for idx < len(days) && days[idx].Day.Before(sessionDay) && !sameDay(days[idx].Day, sessionDay) {
+			merged = append(merged, days[idx])
+			idx++
+		}
+		if idx < len(days) && sameDay(days[idx].Day, sessionDay) {
+			continue
+		}
+		merged = append(merged, &DailyResult{Day: sessionDay})
+	}
+	return append(merged, days[idx:]...)
+}
+
+// streakDays returns the days that count toward a user's streak: the daily results
+// plus the days they ran a practice session. Sessions have no category, so filtered
+// results are returned as they are.
+func streakDays(ctx context.Context, userID int, days []*DailyResult, filter StatsFilter, timezone string) ([]*DailyResult, error) {
+	if filter != (StatsFilter{}) {
+		return days, nil
+	}
+
+	sessionDays, err := GetUserSessionDays(ctx, userID, timezone)
+	if err != nil {
+		return nil, err
+	}
+	return addSessionDays(days, sessionDays), nil
+}
+
+// startOfWeek returns midnight on the Monday of t's week, in t's location
+func startOfWeek(t time.Time) time.Time {
+	weekday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-weekday, 0, 0, 0, 0, t.Location())
+}
+
+// sameDay reports whether two times fall on the same calendar date
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
+
+// winRatePercent returns wins as a percentage of decided games (wins plus losses), or
+// 0 when there are none. Draws are left out; see Outcome.
+func winRatePercent(wins, losses int) float64 {
+	if wins+losses == 0 {
+		return 0
+	}
+	return 100 * float64(wins) / float64(wins+losses)
+}
+
+// ActivityEntry is one member on a guild's activity leaderboard
+type ActivityEntry struct {
+	DiscordID string `json:"discord_id"`
+	Username  string `json:"username"`
+	Games     int    `json:"games"`
+	Wins      int    `json:"wins"`
+	Losses    int    `json:"losses"`
+	Days      int    `json:"days"`
+}
+
+// GetGuildActivityLeaderboard returns the guild members with the most games matching
+// the filter, along with how many distinct days they played
+func GetGuildActivityLeaderboard(ctx context.Context, guildID string, filter StatsFilter, limit int) ([]*ActivityEntry, error) {
+	query := `
+		SELECT u.discord_id, u.username,
+			COUNT(*) AS games,
+			COUNT(*) FILTER (WHERE pg.` + winOutcomeFilter + `) AS wins,
+			COUNT(*) FILTER (WHERE pg.` + lossOutcomeFilter + `) AS losses,
+			COUNT(DISTINCT (pg.created_at AT TIME ZONE u.timezone)::date) AS days
+		FROM (
+			SELECT * FROM player_games
+			WHERE guild_id = $1 AND ` + statsFilterClause + ` AND ` + countedGamesFilter + `
+		) pg
+		JOIN users u ON u.id = pg.user_id
+		GROUP BY u.id
+		ORDER BY games DESC, wins DESC, u.username
+		LIMIT $5
+	`
+
+	args := append([]any{guildID}, filter.args()...)
+	rows, err := DB.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*ActivityEntry
+	for rows.Next() {
+		entry := &ActivityEntry{}
+		err = rows.Scan(&entry.DiscordID, &entry.Username, &entry.Games, &entry.Wins, &entry.Losses, &entry.Days)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan activity entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get activity leaderboard: %w", err)
+	}
+
+	return entries, nil
+}
+
+// TempoMatchup is the average game length for one leader against one opponent leader.
+// Averages are nil when no game in the matchup recorded that detail.
+type TempoMatchup struct {
+	Leader             string   `json:"leader"`
+	Opponent           string   `json:"opponent"`
+	Games              int      `json:"games"`
+	AvgTurns           *float64 `json:"avg_turns,omitempty"`
+	AvgDurationMinutes *float64 `json:"avg_duration_minutes,omitempty"`
+}
+
+// TempoReport summarizes how long a user's games last and how they end, using the
+// optional game details. Only games with at least one detail recorded are included.
+type TempoReport struct {
+	Games              int      `json:"games"`
+	AvgTurns           *float64 `json:"avg_turns,omitempty"`
+	AvgDurationMinutes *float64 `json:"avg_duration_minutes,omitempty"`
+	// AvgLifeOnWins is the user's life left in wins; AvgOpponentLifeOnLosses is the
+	// opponent's life left in losses
+	AvgLifeOnWins           *float64 `json:"avg_life_on_wins,omitempty"`
+	AvgOpponentLifeOnLosses *float64 `json:"avg_opponent_life_on_losses,omitempty"`
+	// FastLosses counts losses that ended within FastTurns turns, out of the
+	// TimedLosses that recorded a turn count
+	FastTurns   int             `json:"fast_turns"`
+	FastLosses  int             `json:"fast_losses"`
+	TimedLosses int             `json:"timed_losses"`
+	Matchups    []*TempoMatchup `json:"matchups"`
+}
+
+// tempoDetailFilter limits player_games to games with at least one tempo detail
+const tempoDetailFilter = `(turns IS NOT NULL OR life_remaining IS NOT NULL
+			OR opponent_life_remaining IS NOT NULL OR duration_minutes IS NOT NULL)`
+
+// GetUserTempo builds a user's tempo report for the games matching the filter. A loss
+// is fast when the game lasted fastTurns turns or fewer.
+func GetUserTempo(ctx context.Context, userID int, filter StatsFilter, fastTurns int) (*TempoReport, error) {
+	args := append([]any{userID}, filter.args()...)
+
+	query := `
+		SELECT COUNT(*),
+			AVG(turns),
+			AVG(duration_minutes),
+			AVG(life_remaining) FILTER (WHERE ` + winOutcomeFilter + `),
+			AVG(opponent_life_remaining) FILTER (WHERE ` + lossOutcomeFilter + `),
+			COUNT(*) FILTER (WHERE ` + lossOutcomeFilter + ` AND turns <= $5),
+			COUNT(*) FILTER (WHERE ` + lossOutcomeFilter + ` AND turns IS NOT NULL)
+		FROM player_games
+		WHERE user_id = $1 AND ` + statsFilterClause + ` AND ` + countedGamesFilter + `
+			AND ` + tempoDetailFilter
+
+	report := &TempoReport{FastTurns: fastTurns}
+	err := DB.QueryRowContext(ctx, query, append(args, fastTurns)...).Scan(
+		&report.Games,
+		&report.AvgTurns,
+		&report.AvgDurationMinutes,
+		&report.AvgLifeOnWins,
+		&report.AvgOpponentLifeOnLosses,
+		&report.FastLosses,
+		&report.TimedLosses,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tempo summary: %w", err)
+	}
+
+	query = `
+		SELECT MODE() WITHIN GROUP (ORDER BY TRIM(leader)) AS leader,
+			MODE() WITHIN GROUP (ORDER BY TRIM(opponent)) AS opponent,
+			COUNT(*) AS games,
+			AVG(turns),
+			AVG(duration_minutes)
+		FROM player_games
+		WHERE user_id = $1 AND ` + statsFilterClause + ` AND ` + countedGamesFilter + `
+			AND (turns IS NOT NULL OR duration_minutes IS NOT NULL)
+		GROUP BY LOWER(TRIM(leader)), LOWER(TRIM(opponent))
+		ORDER BY games DESC, leader, opponent
+	`
+
+	rows, err := DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tempo matchups: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		matchup := &TempoMatchup{}
+		err = rows.Scan(&matchup.Leader, &matchup.Opponent, &matchup.Games, &matchup.AvgTurns, &matchup.AvgDurationMinutes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tempo matchup: %w", err)
+		}
+		report.Matchups = append(report.Matchups, matchup)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get tempo matchups: %w", err)
+	}
+
+	return report, nil
+}
+
+// MulliganRecord is the record of games after one opening hand decision
+type MulliganRecord struct {
+	Games  int `json:"games"`
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+	// AvgKeyCards is the average number of key cards opened with, or nil if no game
+	// recorded it
+	AvgKeyCards *float64 `json:"avg_key_cards,omitempty"`
+
+	keyCardTotal int
+	keyCardGames int
+}
+
+// add counts a group of games into the record
+func (r *MulliganRecord) add(games, wins, losses, draws, keyCardTotal, keyCardGames int) {
+	r.Games += games
+	r.Wins += wins
+	r.Losses += losses
+	r.Draws += draws
+	r.keyCardTotal += keyCardTotal
+	r.keyCardGames += keyCardGames
+	if r.keyCardGames > 0 {
+		avg := float64(r.keyCardTotal) / float64(r.keyCardGames)
+		r.AvgKeyCards = &avg
+	}
+}
+
+// MulliganSplit compares games where the opening hand was kept against games where
+// it was mulliganed
+type MulliganSplit struct {
+	Kept       MulliganRecord `json:"kept"`
+	Mulliganed MulliganRecord `json:"mulliganed"`
+}
+
+// add counts a group of games into the side of the split matching mulliganed
+func (s *MulliganSplit) add(mulliganed bool, games, wins, losses, draws, keyCardTotal, keyCardGames int) {
+	if mulliganed {
+		s.Mulliganed.add(games, wins, losses, draws, keyCardTotal, keyCardGames)
+	} else {
+		s.Kept.add(games, wins, losses, draws, keyCardTotal, keyCardGames)
+	}
+}
+
+// LeaderMulligan is the keep vs mulligan split for one of the user's leaders
+type LeaderMulligan struct {
+	Leader string `json:"leader"`
+	MulliganSplit
+}
+
+// MulliganReport compares a user's results after keeping and after mulliganing,
+// overall, by turn order and by leader. Only games where the mulligan decision was
+// recorded are included.
+type MulliganReport struct {
+	Overall MulliganSplit     `json:"overall"`
+	First   MulliganSplit     `json:"first"`
+	Second  MulliganSplit     `json:"second"`
+	Leaders []*LeaderMulligan `json:"leaders"`
+}
+
+// GetUserMulligans builds a user's mulligan report for the games matching the filter.
+// Leaders are grouped case-insensitively and ordered by games played.
+func GetUserMulligans(ctx context.Context, userID int, filter StatsFilter) (*MulliganReport, error) {
+	query := `
+		SELECT MODE() WITHIN GROUP (ORDER BY TRIM(leader)) AS leader,
+			went_first,
+			mulliganed,
+			COUNT(*) AS games,
+			COUNT(*) FILTER (WHERE ` + winOutcomeFilter + `) AS wins,
+			COUNT(*) FILTER (WHERE ` + lossOutcomeFilter + `) AS losses,
+			COUNT(*) FILTER (WHERE ` + drawOutcomeFilter + `) AS draws,
+			COALESCE(SUM(key_cards), 0) AS key_card_total,
+			COUNT(key_cards) AS key_card_games
+		FROM player_games
+		WHERE user_id = $1 AND ` + statsFilterClause + ` AND ` + countedGamesFilter + `
+			AND mulliganed IS NOT NULL
+		GROUP BY LOWER(TRIM(leader)), went_first, mulliganed
+	`
+
+	rows, err := DB.QueryContext(ctx, query, append([]any{userID}, filter.args()...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user mulligans: %w", err)
+	}
+	defer rows.Close()
+
+	report := &MulliganReport{}
+	leaders := map[string]*LeaderMulligan{}
+	for rows.Next() {
+		var leader string
+		var wentFirst, mulliganed bool
+		var games, wins, losses, draws, keyCardTotal, keyCardGames int
+		err = rows.Scan(&leader, &wentFirst, &mulliganed, &games, &wins, &losses, &draws, &keyCardTotal, &keyCardGames)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan mulligan record: %w", err)
+		}
+
+		report.Overall.add(mulliganed, games, wins, losses, draws, keyCardTotal, keyCardGames)
+		if wentFirst {
+			report.First.add(mulliganed, games, wins, losses, draws, keyCardTotal, keyCardGames)
+		} else {
+			report.Second.add(mulliganed, games, wins, losses, draws, keyCardTotal, keyCardGames)
+		}
+
+		key := strings.ToLower(leader)
+		entry, ok := leaders[key]
+		if !ok {
+			entry = &LeaderMulligan{Leader: leader}
+			leaders[key] = entry
+			report.Leaders = append(report.Leaders, entry)
+		}
+		entry.add(mulliganed, games, wins, losses, draws, keyCardTotal, keyCardGames)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user mulligans: %w", err)
+	}
+
+	sort.SliceStable(report.Leaders, func(a, b int) bool {
+		gamesA := report.Leaders[a].Kept.Games + report.Leaders[a].Mulliganed.Games
+		gamesB := report.Leaders[b].Kept.Games + report.Leaders[b].Mulliganed.Games
+		if gamesA != gamesB {
+			return gamesA > gamesB
+		}
+		return report.Leaders[a].Leader < report.Leaders[b].Leader
+	})
+
+	return report, nil
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// matchupsMaxLines is the number of matchups listed in the /matchups text reply
+	matchupsMaxLines = 15
+	// heatmapMaxLeaders and heatmapMaxOpponents bound the matchup heatmap size
+	heatmapMaxLeaders   = 8
+	heatmapMaxOpponents = 10
+	// streakCalendarWeeks is how far back the /streak calendar goes
+	streakCalendarWeeks = 26
+	// tempoFastTurns is the turn count at or under which /tempo calls a loss fast
+	tempoFastTurns = 6
+	// tempoMaxLines is the number of matchups listed by /tempo
+	tempoMaxLines = 15
+	// mulliganMaxLeaders is the number of leaders listed by /mulligan-stats
+	mulliganMaxLeaders = 10
+)
+
+// userLocation returns the user's configured timezone, falling back to UTC
+func userLocation(user *User) *time.Location {
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// sendChartFollowup sends a followup message with a PNG chart attached. If the chart
+// failed to render, the message is sent on its own.
+func sendChartFollowup(c *commandContext, content, filename string, chart []byte) {
+	params := &discordgo.WebhookParams{
+		Content: content,
+	}
+	if chart != nil {
+		params.Files = []*discordgo.File{
+			{
+				Name:        filename,
+				ContentType: "image/png",
+				Reader:      bytes.NewReader(chart),
+			},
+		}
+	}
+
+	c.Send(params)
+}
+
+// cumulativeWinRate turns daily results into the running win rate after each day
+func cumulativeWinRate(days []*DailyResult) []WinRatePoint {
+	var points []WinRatePoint
+	wins, losses := 0, 0
+	for _, day := range days {
+		wins += day.Wins
+		losses += day.Losses
+		points = append(points, WinRatePoint{Day: day.Day, WinRate: winRatePercent(wins, losses)})
+	}
+	return points
+}
+
+// statsFilterOptions narrows a stats command to one category
+type statsFilterOptions struct {
+	Category string `option:"category" description:"Only include games in this category" autocomplete:"category"`
+}
+
+var statsCommand = &slashCommand[statsFilterOptions]{
+	Name:             "stats",
+	Description:      "Show your record and win rate over time",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runStats,
+}
+
+func runStats(ctx context.Context, c *commandContext, options *statsFilterOptions) error {
+	const failure = "❌ Failed to load stats. Please try again later."
+	category := NormalizeCategory(options.Category)
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	summary, err := GetUserSummary(ctx, user.ID, StatsFilter{Category: category})
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to get user summary: %w", err))
+	}
+
+	days, err := GetUserDailyResults(ctx, user.ID, StatsFilter{Category: category}, userLocation(user).String())
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to get daily results: %w", err))
+	}
+
+	scope := "All categories"
+	if category != "" {
+		scope = category
+	}
+
+	if summary.Games == 0 {
+		c.Replyf("📊 No games recorded yet (%s). Use `/record-game` to get started!", scope)
+		return nil
+	}
+
+	content := fmt.Sprintf("📊 **Stats for %s** (%s)\n🎮 Games: **%d** • Record: **%s** • Win rate: **%.1f%%**\n🥇 Going first: %s (%.1f%%)\n🥈 Going second: %s (%.1f%%)\n🤝 Confirmed games vs members: %d",
+		user.Username, scope,
+		summary.Games, formatRecord(summary.Wins, summary.Losses, summary.Draws), winRatePercent(summary.Wins, summary.Losses),
+		formatRecord(summary.FirstWins, summary.FirstLosses, summary.FirstGames-summary.FirstWins-summary.FirstLosses), winRatePercent(summary.FirstWins, summary.FirstLosses),
+		formatRecord(summary.SecondWins, summary.SecondLosses, summary.SecondGames-summary.SecondWins-summary.SecondLosses), winRatePercent(summary.SecondWins, summary.SecondLosses),
+		summary.ConfirmedPvP)
+	week, err := weekPracticeLine(ctx, user)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+	if week != "" {
+		content += "\n" + week
+	}
+	if summary.Draws > 0 {
+		content += "\nℹ️ Records are wins-losses-draws. Draws don't count toward win rate."
+	}
+
+	chart, err := renderWinRateChart(fmt.Sprintf("Win rate over time - %s", scope), cumulativeWinRate(days))
+	if err != nil {
+		c.Logger().Error("Failed to render win rate chart", "error", err)
+	}
+
+	sendChartFollowup(c, content, "win-rate.png", chart)
+	return nil
+}
+
+var matchupsCommand = &slashCommand[statsFilterOptions]{
+	Name:             "matchups",
+	Description:      "Show your record for each leader against each opponent leader",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runMatchups,
+}
+
+func runMatchups(ctx context.Context, c *commandContext, options *statsFilterOptions) error {
+	const failure = "❌ Failed to load matchups. Please try again later."
+	category := NormalizeCategory(options.Category)
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	matchups, err := GetUserMatchups(ctx, user.ID, StatsFilter{Category: category})
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to get user matchups: %w", err))
+	}
+
+	scope := "All categories"
+	if category != "" {
+		scope = category
+	}
+
+	if len(matchups) == 0 {
+		c.Replyf("⚔️ No games recorded yet (%s). Use `/record-game` to get started!", scope)
+		return nil
+	}
+
+	var lines []string
+	for idx, matchup := range matchups {
+		if idx == matchupsMaxLines {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(matchups)-matchupsMaxLines))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("• **%s** vs **%s**: %s (%.0f%%)",
+			matchup.Leader, matchup.Opponent, formatRecord(matchup.Wins, matchup.Losses, matchup.Draws), winRatePercent(matchup.Wins, matchup.Losses)))
+	}
+	content := fmt.Sprintf("⚔️ **Matchups for %s** (%s)\n\n%s", user.Username, scope, strings.Join(lines, "\n"))
+
+	leaders, opponents, records := matchupGrid(matchups)
+	chart, err := renderMatchupHeatmap(fmt.Sprintf("Matchups - %s", scope), leaders, opponents, records)
+	if err != nil {
+		c.Logger().Error("Failed to render matchup heatmap", "error", err)
+	}
+
+	sendChartFollowup(c, content, "matchups.png", chart)
+	return nil
+}
+
+// matchupGrid picks the most played leaders and opponent leaders for the heatmap
+func matchupGrid(matchups []*MatchupRecord) ([]string, []string, map[[2]string]*MatchupRecord) {
+	leaderGames := map[string]int{}
+	opponentGames := map[string]int{}
+	records := map[[2]string]*MatchupRecord{}
+	for _, matchup := range matchups {
+		leaderGames[matchup.Leader] += matchup.Games
+		opponentGames[matchup.Opponent] += matchup.Games
+		records[[2]string{matchup.Leader, matchup.Opponent}] = matchup
+	}
+
+	return mostPlayed(leaderGames, heatmapMaxLeaders), mostPlayed(opponentGames, heatmapMaxOpponents), records
+}
+
+// mostPlayed returns up to limit names with the most games, most played first
+func mostPlayed(games map[string]int, limit int) []string {
+	names := make([]string, 0, len(games))
+	for name := range games {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(a, b int) bool {
+		if games[names[a]] != games[names[b]] {
+			return games[names[a]] > games[names[b]]
+		}
+		return names[a] < names[b]
+	})
+	if len(names) > limit {
+		names = names[:limit]
+	}
+	return names
+}
+
+var streakCommand = &slashCommand[noOptions]{
+	Name:             "streak",
+	Description:      "Show your practice streak and games per day",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runStreak,
+}
+
+func runStreak(ctx context.Context, c *commandContext, options *noOptions) error {
+	const failure = "❌ Failed to load your streak. Please try again later."
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	loc := userLocation(user)
+	days, err := GetUserDailyResults(ctx, user.ID, StatsFilter{}, loc.String())
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to get daily results: %w", err))
+	}
+
+	practiceDays, err := streakDays(ctx, user.ID, days, StatsFilter{}, loc.String())
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to get session days: %w", err))
+	}
+
+	today := time.Now().In(loc)
+	streak := calculateStreak(practiceDays, today)
+
+	var content string
+	if streak.PracticeDays == 0 {
+		content = "🔥 No practice days yet. Record a game with `/record-game` or run `/session start` to start a streak!"
+	} else {
+		status := "Play today to keep it going!"
+		if streak.Current == 0 {
+			status = "Your streak has ended. Play today to start a new one!"
+		} else if sameDay(streak.LastPlayed, today) {
+			status = "You've practiced today. ✅"
+		}
+		content = fmt.Sprintf("🔥 **Streak for %s**\n📅 Current streak: **%d** day(s)\n🏆 Longest streak: **%d** day(s)\n🗓️ Practice days: **%d** • Last played: %s\n%s",
+			user.Username, streak.Current, streak.Longest, streak.PracticeDays, streak.LastPlayed.Format("Jan 2, 2006"), status)
+
+		week, err := weekPracticeLine(ctx, user)
+		if err != nil {
+			return commandFailed(failure, err)
+		}
+		if week != "" {
+			content += "\n" + week
+		}
+	}
+
+	gamesPerDay := map[string]int{}
+	for _, day := range days {
+		gamesPerDay[day.Day.Format("2006-01-02")] = day.Games
+	}
+	chart, err := renderCalendarHeatmap("Games per day", gamesPerDay, today, streakCalendarWeeks)
+	if err != nil {
+		c.Logger().Error("Failed to render calendar heatmap", "error", err)
+	}
+
+	sendChartFollowup(c, content, "streak.png", chart)
+	return nil
+}
+
+var tempoCommand = &slashCommand[statsFilterOptions]{
+	Name:             "tempo",
+	Description:      "Show how long your games last and how often you lose early",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runTempo,
+}
+
+// formatAverage formats an average detail, or "–" if no game recorded it
+func formatAverage(avg *float64, unit string) string {
+	if avg == nil {
+		return "–"
+	}
+	return fmt.Sprintf("%.1f %s", *avg, unit)
+}
+
+func runTempo(ctx context.Context, c *commandContext, options *statsFilterOptions) error {
+	const failure = "❌ Failed to load your tempo report. Please try again later."
+	category := NormalizeCategory(options.Category)
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	report, err := GetUserTempo(ctx, user.ID, StatsFilter{Category: category}, tempoFastTurns)
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to get user tempo: %w", err))
+	}
+
+	scope := "All categories"
+	if category != "" {
+		scope = category
+	}
+
+	if report.Games == 0 {
+		c.Replyf("⏱️ No games with turns, life or duration recorded yet (%s). Add them with the optional `turns`, `life`, `opponent_life` and `duration` options of `/record-game`.", scope)
+		return nil
+	}
+
+	fastLosses := "no losses with a turn count yet"
+	if report.TimedLosses > 0 {
+		fastLosses = fmt.Sprintf("%d of %d losses (%.0f%%)", report.FastLosses, report.TimedLosses,
+			100*float64(report.FastLosses)/float64(report.TimedLosses))
+	}
+
+	content := fmt.Sprintf("⏱️ **Tempo for %s** (%s)\n🎮 Games with details: **%d**\n🔄 Average length: **%s** • **%s**\n❤️ Life left: **%s** on wins • opponent **%s** on losses\n⚡ Lost by turn %d: %s",
+		user.Username, scope, report.Games,
+		formatAverage(report.AvgTurns, "turns"), formatAverage(report.AvgDurationMinutes, "min"),
+		formatAverage(report.AvgLifeOnWins, "life"), formatAverage(report.AvgOpponentLifeOnLosses, "life"),
+		report.FastTurns, fastLosses)
+
+	if len(report.Matchups) > 0 {
+		var lines []string
+		for idx, matchup := range report.Matchups {
+			if idx == tempoMaxLines {
+				lines = append(lines, fmt.Sprintf("…and %d more", len(report.Matchups)-tempoMaxLines))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("• **%s** vs **%s**: %s • %s (%d games)",
+				matchup.Leader, matchup.Opponent,
+				formatAverage(matchup.AvgTurns, "turns"), formatAverage(matchup.AvgDurationMinutes, "min"), matchup.Games))
+		}
+		content += "\n\n**Average game length by matchup**\n" + strings.Join(lines, "\n")
+	}
+
+	c.Reply(content)
+	return nil
+}
+
+var mulliganStatsCommand = &slashCommand[statsFilterOptions]{
+	Name:             "mulligan-stats",
+	Description:      "Compare your win rate after keeping and after mulliganing",
+	Contexts:         anywhereContexts,
+	IntegrationTypes: personalInstallTypes,
+	Run:              runMulliganStats,
+}
+
+// formatMulliganRecord formats one side of a keep vs mulligan split
+func formatMulliganRecord(record MulliganRecord) string {
+	if record.Games == 0 {
+		return "no games"
+	}
+	text := fmt.Sprintf("**%.0f%%** (%s)", winRatePercent(record.Wins, record.Losses), formatRecord(record.Wins, record.Losses, record.Draws))
+	if record.AvgKeyCards != nil {
+		text += fmt.Sprintf(" • %.1f key cards", *record.AvgKeyCards)
+	}
+	return text
+}
+
+// formatMulliganSplit formats a keep vs mulligan split on one line
+func formatMulliganSplit(split MulliganSplit) string {
+	return fmt.Sprintf("kept %s • mulliganed %s", formatMulliganRecord(split.Kept), formatMulliganRecord(split.Mulliganed))
+}
+
+func runMulliganStats(ctx context.Context, c *commandContext, options *statsFilterOptions) error {
+	const failure = "❌ Failed to load your mulligan stats. Please try again later."
+	category := NormalizeCategory(options.Category)
+
+	user, err := c.User(ctx)
+	if err != nil {
+		return commandFailed(failure, err)
+	}
+
+	report, err := GetUserMulligans(ctx, user.ID, StatsFilter{Category: category})
+	if err != nil {
+		return commandFailed(failure, fmt.Errorf("failed to get user mulligans: %w", err))
+	}
+
+	scope := "All categories"
+	if category != "" {
+		scope = category
+	}
+
+	if len(report.Leaders) == 0 {
+		c.Replyf("🃏 No games with a mulligan decision recorded yet (%s). Add one with the optional `mulliganed` and `key_cards` options of `/record-game`.", scope)
+		return nil
+	}
+
+	var lines []string
+	for idx, leader := range report.Leaders {
+		if idx == mulliganMaxLeaders {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(report.Leaders)-mulliganMaxLeaders))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("• **%s**: %s", leader.Leader, formatMulliganSplit(leader.MulliganSplit)))
+	}
+
+	c.Replyf("🃏 **Mulligan stats for %s** (%s)\n✋ Kept: %s • %d games\n🔄 Mulliganed: %s • %d games\n🥇 Going first: %s\n🥈 Going second: %s\n\n**By leader**\n%s",
+		user.Username, scope,
+		formatMulliganRecord(report.Overall.Kept), report.Overall.Kept.Games,
+		formatMulliganRecord(report.Overall.Mulliganed), report.Overall.Mulliganed.Games,
+		formatMulliganSplit(report.First), formatMulliganSplit(report.Second),
+		strings.Join(lines, "\n"))
+	return nil
+}
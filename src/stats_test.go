@@ -0,0 +1,225 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// day returns midnight UTC on the given date in October 2026
+func day(d int) time.Time {
+	return time.Date(2026, time.October, d, 0, 0, 0, 0, time.UTC)
+}
+
+// dailyResults builds daily results with games on the given October dates
+func dailyResults(days ...int) []*DailyResult {
+	var results []*DailyResult
+	for _, d := range days {
+		results = append(results, &DailyResult{Day: day(d), Games: 1})
+	}
+	return results
+}
+
+func TestCalculateStreak(t *testing.T) {
+	today := time.Date(2026, time.October, 19, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		days []*DailyResult
+		want Streak
+	}{
+		{
+			name: "no games",
+			want: Streak{},
+		},
+		{
+			name: "played today",
+			days: dailyResults(17, 18, 19),
+			want: Streak{Current: 3, Longest: 3, PracticeDays: 3, LastPlayed: day(19)},
+		},
+		{
+			name: "played yesterday keeps the streak alive",
+			days: dailyResults(16, 17, 18),
+			want: Streak{Current: 3, Longest: 3, PracticeDays: 3, LastPlayed: day(18)},
+		},
+		{
+			name: "missing yesterday ends the streak",
+			days: dailyResults(15, 16, 17),
+			want: Streak{Current: 0, Longest: 3, PracticeDays: 3, LastPlayed: day(17)},
+		},
+		{
+			name: "longest run is kept after a gap",
+			days: dailyResults(1, 2, 3, 4, 10, 18, 19),
+			want: Streak{Current: 2, Longest: 4, PracticeDays: 7, LastPlayed: day(19)},
+		},
+		{
+			name: "runs cross month ends",
+			days: []*DailyResult{
+				{Day: time.Date(2026, time.September, 29, 0, 0, 0, 0, time.UTC)},
+				{Day: time.Date(2026, time.September, 30, 0, 0, 0, 0, time.UTC)},
+				{Day: day(1)},
+			},
+			want: Streak{Current: 0, Longest: 3, PracticeDays: 3, LastPlayed: day(1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calculateStreak(tt.days, today); got != tt.want {
+				t.Errorf("calculateStreak() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddSessionDays(t *testing.T) {
+	tests := []struct {
+		name        string
+		days        []*DailyResult
+		sessionDays []time.Time
+		wantDays    []int
+		wantGames   []int
+	}{
+		{
+			name:      "no sessions",
+			days:      dailyResults(1, 3),
+			wantDays:  []int{1, 3},
+			wantGames: []int{1, 1},
+		},
+		{
+			name:        "only sessions",
+			sessionDays: []time.Time{day(2), day(4)},
+			wantDays:    []int{2, 4},
+			wantGames:   []int{0, 0},
+		},
+		{
+			name:        "session days fill the gaps in order",
+			days:        dailyResults(1, 4, 6),
+			sessionDays: []time.Time{day(2), day(3), day(5), day(7)},
+			wantDays:    []int{1, 2, 3, 4, 5, 6, 7},
+			wantGames:   []int{1, 0, 0, 1, 0, 1, 0},
+		},
+		{
+			name:        "session on a day with games keeps the games",
+			days:        dailyResults(1, 2),
+			sessionDays: []time.Time{day(2)},
+			wantDays:    []int{1, 2},
+			wantGames:   []int{1, 1},
+		},
+		{
+			name:        "session before every game day",
+			days:        dailyResults(5, 6),
+			sessionDays: []time.Time{day(1)},
+			wantDays:    []int{1, 5, 6},
+			wantGames:   []int{0, 1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := addSessionDays(tt.days, tt.sessionDays)
+			if len(got) != len(tt.wantDays) {
+				t.Fatalf("addSessionDays() returned %d days, want %d", len(got), len(tt.wantDays))
+			}
+			for idx, result := range got {
+				if !result.Day.Equal(day(tt.wantDays[idx])) || result.Games != tt.wantGames[idx] {
+					t.Errorf("day %d = %s with %d games, want October %d with %d games",
+						idx, result.Day.Format("2006-01-02"), result.Games, tt.wantDays[idx], tt.wantGames[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestAddSessionDaysCountTowardStreak(t *testing.T) {
+	today := day(19)
+	days := addSessionDays(dailyResults(16, 19), []time.Time{day(17), day(18)})
+
+	streak := calculateStreak(days, today)
+	if streak.Current != 4 {
+		t.Errorf("Current = %d, want 4 with session days bridging the gap", streak.Current)
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"monday", time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC), day(19)},
+		{"sunday", time.Date(2026, time.October, 25, 23, 59, 0, 0, time.UTC), day(19)},
+		{"wednesday", time.Date(2026, time.October, 21, 12, 0, 0, 0, time.UTC), day(19)},
+		{"across a month", time.Date(2026, time.October, 2, 12, 0, 0, 0, time.UTC), time.Date(2026, time.September, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := startOfWeek(tt.t); !got.Equal(tt.want) {
+				t.Errorf("startOfWeek() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStatsFilter(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+
+	tests := []struct {
+		name               string
+		category, from, to string
+		want               StatsFilter
+		wantErr            string
+	}{
+		{
+			name: "no filter",
+			want: StatsFilter{},
+		},
+		{
+			name:     "category is normalized",
+			category: " ranked ",
+			want:     StatsFilter{Category: "Ranked"},
+		},
+		{
+			name: "dates are inclusive days in the location",
+			from: "2026-10-01",
+			to:   "2026-10-19",
+			want: StatsFilter{
+				From: time.Date(2026, time.October, 1, 0, 0, 0, 0, loc),
+				To:   time.Date(2026, time.October, 20, 0, 0, 0, 0, loc),
+			},
+		},
+		{
+			name:     "blank category",
+			category: "   ",
+			wantErr:  "invalid category",
+		},
+		{
+			name:    "bad from date",
+			from:    "01/10/2026",
+			wantErr: "invalid from date",
+		},
+		{
+			name:    "bad to date",
+			to:      "2026-13-01",
+			wantErr: "invalid to date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatsFilter(tt.category, tt.from, tt.to, loc)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseStatsFilter() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStatsFilter() error = %v", err)
+			}
+			if got.Category != tt.want.Category || !got.From.Equal(tt.want.From) || !got.To.Equal(tt.want.To) {
+				t.Errorf("parseStatsFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}